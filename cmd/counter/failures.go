@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+)
+
+// writeFailures writes failures as CSV with the URL first, so the file can
+// be passed back with -input to retry them.
+func writeFailures(w io.Writer, failures []fetcher.FailedURL) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"url", "retries", "error"}); err != nil {
+		return err
+	}
+	for _, failure := range failures {
+		if err := writer.Write([]string{failure.URL, strconv.Itoa(failure.RetryCount), failure.Error}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func saveFailures(path string, failures []fetcher.FailedURL) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeFailures(file, failures); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFailures(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeFailures(&buf, []fetcher.FailedURL{
+		{URL: "http://example.com/a", Error: "unexpected status: 500", RetryCount: 2},
+		{URL: "http://example.com/b", Error: "skipped: 3 words, fewer than 5"},
+	}))
+
+	assert.Equal(t, `url,retries,error
+http://example.com/a,2,unexpected status: 500
+http://example.com/b,0,"skipped: 3 words, fewer than 5"
+`, buf.String())
+}
+
+func TestSaveFailuresRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failures.csv")
+	require.NoError(t, saveFailures(path, []fetcher.FailedURL{{URL: "http://example.com/a", Error: "timeout"}}))
+
+	urls, err := loadURLs(path, options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://example.com/a"}, urls)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+const (
+	formatJSON  = "json"
+	formatCSV   = "csv"
+	formatTable = "table"
+)
+
+func isValidFormat(format string) bool {
+	switch format {
+	case formatJSON, formatCSV, formatTable:
+		return true
+	}
+	return false
+}
+
+// FormatResults writes the results to w. JSON includes everything, while CSV
+// and table only list the top words and their counts, per host when the
+// results have hosts.
+func FormatResults(w io.Writer, format string, output finalResults) error {
+	switch format {
+	case formatJSON:
+		return formatResultsJSON(w, output)
+	case formatCSV:
+		return formatResultsCSV(w, output)
+	case formatTable:
+		return formatResultsTable(w, output)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func formatResultsJSON(w io.Writer, output finalResults) error {
+	jsonOutput, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(jsonOutput))
+	return err
+}
+
+func formatResultsCSV(w io.Writer, output finalResults) error {
+	cw := csv.NewWriter(w)
+	if len(output.Hosts) > 0 {
+		if err := cw.Write([]string{"host", "word", "count"}); err != nil {
+			return err
+		}
+		for _, host := range output.Hosts {
+			for _, wc := range host.TopWords {
+				if err := cw.Write([]string{host.Host, wc.Word, strconv.Itoa(wc.Count)}); err != nil {
+					return err
+				}
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	if err := cw.Write([]string{"word", "count"}); err != nil {
+		return err
+	}
+	for _, wc := range output.TopWords {
+		if err := cw.Write([]string{wc.Word, strconv.Itoa(wc.Count)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatResultsTable(w io.Writer, output finalResults) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	total := 0
+	fmt.Fprintln(tw, "WORD\tCOUNT")
+	fmt.Fprintln(tw, "----\t-----")
+	for _, wc := range output.TopWords {
+		fmt.Fprintf(tw, "%s\t%d\n", wc.Word, wc.Count)
+		total += wc.Count
+	}
+	fmt.Fprintln(tw, "----\t-----")
+	fmt.Fprintf(tw, "TOTAL\t%d\n", total)
+
+	for _, host := range output.Hosts {
+		fmt.Fprintf(tw, "\n%s\n", host.Host)
+		for _, wc := range host.TopWords {
+			fmt.Fprintf(tw, "%s\t%d\n", wc.Word, wc.Count)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResults() finalResults {
+	return finalResults{
+		TopWords: []processor.WordCount{
+			{Word: "technology", Count: 120},
+			{Word: "new", Count: 7},
+		},
+		Metrics: resultMetrics{Processed: 2},
+	}
+}
+
+func TestFormatResultsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, FormatResults(&buf, formatJSON, testResults()))
+
+	var result finalResults
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, testResults().TopWords, result.TopWords)
+	assert.Equal(t, int64(2), result.Metrics.Processed)
+}
+
+func TestFormatResultsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, FormatResults(&buf, formatCSV, testResults()))
+
+	assert.Equal(t, "word,count\ntechnology,120\nnew,7\n", buf.String())
+}
+
+func TestFormatResultsTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, FormatResults(&buf, formatTable, testResults()))
+
+	want := "" +
+		"WORD        COUNT\n" +
+		"----        -----\n" +
+		"technology  120\n" +
+		"new         7\n" +
+		"----        -----\n" +
+		"TOTAL       127\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestFormatResultsPerHost(t *testing.T) {
+	output := testResults()
+	output.Hosts = []hostWords{
+		{Host: "a.com", TopWords: []processor.WordCount{{Word: "technology", Count: 100}}},
+		{Host: "b.com", TopWords: []processor.WordCount{{Word: "technology", Count: 20}, {Word: "new", Count: 7}}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, FormatResults(&buf, formatCSV, output))
+	assert.Equal(t, "host,word,count\na.com,technology,100\nb.com,technology,20\nb.com,new,7\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, FormatResults(&buf, formatTable, output))
+	want := "" +
+		"WORD        COUNT\n" +
+		"----        -----\n" +
+		"technology  120\n" +
+		"new         7\n" +
+		"----        -----\n" +
+		"TOTAL       127\n" +
+		"\n" +
+		"a.com\n" +
+		"technology  100\n" +
+		"\n" +
+		"b.com\n" +
+		"technology  20\n" +
+		"new         7\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestFormatResultsUnknown(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, FormatResults(&buf, "xml", testResults()))
+	assert.False(t, isValidFormat("xml"))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+)
+
+// hostWords is one host's top words in -per-host results.
+type hostWords struct {
+	Host     string                `json:"host"`
+	TopWords []processor.WordCount `json:"top_words"`
+}
+
+// hostCounters counts words separately for each source host.
+type hostCounters struct {
+	mu       sync.Mutex
+	counters map[string]*processor.SafeWordCounter
+}
+
+func newHostCounters() *hostCounters {
+	return &hostCounters{counters: make(map[string]*processor.SafeWordCounter)}
+}
+
+func (h *hostCounters) add(source string, wordCounts map[string]int) {
+	host := source
+	if u, err := url.Parse(source); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	h.mu.Lock()
+	counter, ok := h.counters[host]
+	if !ok {
+		counter = processor.NewSafeWordCounter()
+		h.counters[host] = counter
+	}
+	h.mu.Unlock()
+
+	counter.Accept(wordCounts)
+}
+
+// top returns each host's n most frequent words, sorted by host.
+func (h *hostCounters) top(n int) []hostWords {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hosts := make([]hostWords, 0, len(h.counters))
+	for host, counter := range h.counters {
+		hosts = append(hosts, hostWords{Host: host, TopWords: counter.GetTopWords(n)})
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts
+}
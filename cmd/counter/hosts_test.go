@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostCounters(t *testing.T) {
+	hosts := newHostCounters()
+	hosts.add("https://b.example.com/story", map[string]int{"rocket": 2, "launch": 1})
+	hosts.add("https://a.example.com/one", map[string]int{"market": 1})
+	hosts.add("https://a.example.com/two", map[string]int{"market": 2, "stocks": 1})
+	hosts.add("local/file.html", map[string]int{"offline": 1})
+
+	assert.Equal(t, []hostWords{
+		{Host: "a.example.com", TopWords: []processor.WordCount{{Word: "market", Count: 3}}},
+		{Host: "b.example.com", TopWords: []processor.WordCount{{Word: "rocket", Count: 2}}},
+		{Host: "local/file.html", TopWords: []processor.WordCount{{Word: "offline", Count: 1}}},
+	}, hosts.top(1))
+}
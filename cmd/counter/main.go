@@ -1,65 +1,360 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/shuaibbapputty/word-counter/internal/logging"
 	"github.com/shuaibbapputty/word-counter/internal/processor"
 )
 
 const (
 	defaultNumWorkers = 50
+	defaultTopN       = 10
+	dryRunSample      = 10
 	executionTimeout  = 12 * time.Hour
+	poolCloseTimeout  = 30 * time.Second
+	checkpointFlush   = 10 * time.Second
+	wordBankPath      = "data/input/words.txt"
+	outputDir         = "data/output"
 )
 
+// bundledInputs are the URL lists offered by the interactive prompt, checked
+// by -selftest when no -input is given.
+var bundledInputs = []string{
+	"data/input/1k-endg-urls.txt",
+	"data/input/10k-endg-urls.txt",
+	"data/input/40k-endg-urls.txt",
+}
+
+// logger is where the counter and its fetcher and worker pool log, at the
+// -log-level once the flags are parsed.
+var logger logging.Logger = logging.New(os.Stderr, slog.LevelInfo)
+
+// fatal logs msg as an error and exits with status 1.
+func fatal(msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+type options struct {
+	input       string
+	workers     int
+	top         int
+	timeout     time.Duration
+	output      string
+	quiet       bool
+	format      string
+	state       string
+	noProgress  bool
+	resume      string
+	minWords    int
+	histogram   bool
+	perHost     bool
+	textStats   bool
+	numbers     bool
+	noBank      bool
+	leaders     time.Duration
+	csvColumn   int
+	urlTimeout  time.Duration
+	retryBudget int
+	dryRun      bool
+	weights     processor.Weights
+	limit       int
+	jsonField   string
+	ndjson      string
+	failures    string
+	metrics     string
+	debug       string
+	selfTest    bool
+	logLevel    slog.Level
+
+	snapshot      string
+	snapshotEvery time.Duration
+}
+
+// parseOptions reads the command-line flags. When no flags are given the
+// input is left empty so the caller falls back to the interactive prompt, and
+// results are shown as a table rather than JSON.
+func parseOptions(args []string) (options, error) {
+	var opts options
+
+	flags := flag.NewFlagSet("counter", flag.ContinueOnError)
+	flags.StringVar(&opts.input, "input", "", "path to a file with one URL per line")
+	flags.IntVar(&opts.csvColumn, "csv-column", 0, "zero-based column holding the URLs when -input is a .csv file")
+	flags.StringVar(&opts.jsonField, "json-field", "", "dot-separated field holding the URL array when -input is a .json file")
+	flags.IntVar(&opts.limit, "limit", 0, "process only the first N URLs (0 processes all)")
+	flags.IntVar(&opts.workers, "workers", defaultNumWorkers, "number of word processing workers")
+	flags.IntVar(&opts.top, "top", defaultTopN, "number of top words to report")
+	flags.DurationVar(&opts.timeout, "timeout", executionTimeout, "maximum duration of the whole run")
+	flags.DurationVar(&opts.urlTimeout, "url-timeout", 0, "maximum time spent on one URL including retries (0 disables)")
+	flags.IntVar(&opts.retryBudget, "retry-budget", 0, "maximum retries across all URLs (0 disables the cap)")
+	flags.StringVar(&opts.output, "output", "", "write the results to this file")
+	flags.StringVar(&opts.ndjson, "ndjson", "", "write a JSON line per fetched URL to this file")
+	flags.StringVar(&opts.failures, "failures", "", "write the failed and skipped URLs with their errors as CSV to this file, usable as -input")
+	flags.BoolVar(&opts.quiet, "quiet", false, "don't print the results to stdout when -output is set")
+	flags.StringVar(&opts.state, "state", "", "load word counts from this file and save the combined counts back to it")
+	flags.StringVar(&opts.resume, "resume", "", "checkpoint file of completed URLs to skip and to record progress in (needs -state)")
+	flags.IntVar(&opts.minWords, "min-words", 0, "skip pages with fewer words than this")
+	flags.BoolVar(&opts.histogram, "histogram", false, "include a word-length histogram in JSON results")
+	flags.BoolVar(&opts.perHost, "per-host", false, "also report the top words of each source host")
+	flags.BoolVar(&opts.textStats, "text-stats", false, "include character, word and sentence totals in JSON results")
+	flags.BoolVar(&opts.numbers, "numbers", false, "count numbers such as years apart from words and include the top ones in JSON results")
+	flags.BoolVar(&opts.noBank, "no-bank", false, "count every word instead of only those in the word bank")
+	flags.DurationVar(&opts.leaders, "leaders", 0, "log the current top words at this interval during the run (0 disables)")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "list the URLs that would be fetched and exit")
+	flags.StringVar(&opts.snapshot, "snapshot", "", "periodically write the top words and full counts so far to this JSON file")
+	flags.DurationVar(&opts.snapshotEvery, "snapshot-every", defaultSnapshotInterval, "how often to write -snapshot")
+	weights := flags.String("weights", "", "title,subhead,body multipliers for word counts, e.g. 3,2,1")
+	flags.StringVar(&opts.metrics, "metrics-addr", "", "serve Prometheus metrics at /metrics on this address, e.g. :9090")
+	flags.StringVar(&opts.debug, "debug", "", "fetch this one URL, print its extracted text and counted words, and exit")
+	flags.BoolVar(&opts.selfTest, "selftest", false, "check the input file, word bank, a test fetch and the output directory, then exit")
+	flags.BoolVar(&opts.noProgress, "no-progress", false, "log progress as plain text instead of drawing a progress bar")
+	flags.StringVar(&opts.format, "format", formatJSON, "results format: json, csv or table (defaults to table when run interactively)")
+	logLevel := flags.String("log-level", "info", "lowest level to log: debug, info, warn or error")
+
+	if err := flags.Parse(args); err != nil {
+		return options{}, err
+	}
+	if flags.NFlag() == 0 {
+		opts.format = formatTable
+	}
+	if flags.NFlag() > 0 && opts.input == "" && opts.debug == "" && !opts.selfTest {
+		return options{}, errors.New("-input is required when running with flags")
+	}
+	if opts.resume != "" && opts.state == "" {
+		return options{}, errors.New("-resume needs -state, so the counts of the URLs it skips are kept")
+	}
+	if opts.top <= 0 {
+		return options{}, fmt.Errorf("-top must be positive, got %d", opts.top)
+	}
+	if opts.limit < 0 {
+		return options{}, fmt.Errorf("-limit must not be negative, got %d", opts.limit)
+	}
+	if opts.workers <= 0 {
+		logger.Warn("Invalid -workers, using the default", "workers", opts.workers, "default", defaultNumWorkers)
+		opts.workers = defaultNumWorkers
+	}
+	if opts.snapshotEvery <= 0 {
+		return options{}, fmt.Errorf("-snapshot-every must be positive, got %v", opts.snapshotEvery)
+	}
+	if opts.timeout <= 0 {
+		logger.Warn("Invalid -timeout, using the default", "timeout", opts.timeout, "default", executionTimeout)
+		opts.timeout = executionTimeout
+	}
+	if !isValidFormat(opts.format) {
+		return options{}, fmt.Errorf("unknown -format %q", opts.format)
+	}
+	level, err := logging.ParseLevel(*logLevel)
+	if err != nil {
+		return options{}, err
+	}
+	opts.logLevel = level
+	if *weights != "" {
+		if opts.weights, err = parseWeights(*weights); err != nil {
+			return options{}, err
+		}
+	}
+
+	return opts, nil
+}
+
+// parseWeights reads "title,subhead,body" multipliers, each at least 1.
+func parseWeights(s string) (processor.Weights, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return processor.Weights{}, fmt.Errorf("-weights needs title,subhead,body, got %q", s)
+	}
+
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value < 1 {
+			return processor.Weights{}, fmt.Errorf("-weights values must be positive integers, got %q", part)
+		}
+		values[i] = value
+	}
+	return processor.Weights{Title: values[0], Subhead: values[1], Body: values[2]}, nil
+}
+
 func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	filename := getInputFilename()
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		fatal("Invalid arguments", "err", err)
+	}
+	logger = logging.New(os.Stderr, opts.logLevel)
+
+	if opts.debug != "" {
+		if err := runDebug(os.Stdout, opts); err != nil {
+			fatal("Failed to debug URL", "url", opts.debug, "err", err)
+		}
+		return
+	}
 
-	urls, err := fetcher.FetchFromFile(filename)
+	if opts.selfTest {
+		if !runSelfTest(context.Background(), os.Stdout, selfTestChecks(opts, wordBankPath)) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	filename := opts.input
+	if filename == "" {
+		filename, err = getInputFilename()
+	} else {
+		err = validateInputFile(filename)
+	}
 	if err != nil {
-		log.Fatalf("Failed to load URLs: %v", err)
+		fatal("Failed to select input file", "err", err)
 	}
 
-	startTime := time.Now()
-	log.Printf("Program started at: %v", startTime.Format(time.RFC3339))
+	urls, err := loadURLs(filename, opts)
+	if err != nil {
+		fatal("Failed to load URLs", "err", err)
+	}
 
-	bar := progressbar.Default(int64(len(urls)), "Processing URLs")
+	if opts.resume != "" {
+		completed, err := fetcher.LoadCheckpoint(opts.resume)
+		if err != nil {
+			fatal("Failed to load checkpoint", "err", err)
+		}
+		remaining := fetcher.SkipCompleted(urls, completed)
+		logger.Info("Resuming, skipping completed URLs", "checkpoint", opts.resume, "skipped", len(urls)-len(remaining))
+		urls = remaining
+	}
+
+	if opts.limit > 0 && len(urls) > opts.limit {
+		logger.Info("Limiting the run to the first URLs", "limit", opts.limit, "urls", len(urls))
+		urls = urls[:opts.limit]
+	}
+
+	if opts.dryRun {
+		printDryRun(os.Stdout, urls)
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), executionTimeout)
+	var checkpoint *fetcher.Checkpoint
+	if opts.resume != "" {
+		if checkpoint, err = fetcher.OpenCheckpoint(opts.resume, 0); err != nil {
+			fatal("Failed to open checkpoint", "err", err)
+		}
+		defer func() {
+			if err := checkpoint.Close(); err != nil {
+				logger.Error("Failed to close checkpoint", "err", err)
+			}
+		}()
+	}
+
+	var urlRecords *urlLog
+	if opts.ndjson != "" {
+		if urlRecords, err = createURLLog(opts.ndjson); err != nil {
+			fatal("Failed to create URL log", "err", err)
+		}
+		defer func() {
+			if err := urlRecords.Close(); err != nil {
+				logger.Error("Failed to write URL log", "err", err)
+			}
+		}()
+	}
+
+	startTime := time.Now()
+	logger.Info("Program started", "at", startTime.Format(time.RFC3339))
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
 	defer cancel()
 
 	// Get the validated words from the bank of words
-	wordBank, err := initializeWordBank()
-	if err != nil {
-		log.Fatalf("Failed to initialize word bank: %v", err)
+	var wordBank *processor.ValidWordBank
+	if !opts.noBank {
+		if wordBank, err = initializeWordBank(wordBankPath); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				fatal("Failed to initialize word bank", "err", err)
+			}
+			logger.Warn("Word bank not found, counting every word as with -no-bank", "path", wordBankPath)
+		}
 	}
 
-	pool := processor.NewWorkerPool(wordBank, defaultNumWorkers)
-	pool.Start()
+	wordCounter := processor.NewSafeWordCounter()
+	if opts.state != "" {
+		if wordCounter, err = processor.LoadCounts(opts.state); err != nil {
+			fatal("Failed to load word counts", "err", err)
+		}
+	}
+	docCounter := processor.NewDocumentFrequencyCounter()
+
+	var hosts *hostCounters
+	if opts.perHost {
+		hosts = newHostCounters()
+	}
+
+	var resume *resumeState
+	if checkpoint != nil {
+		resume = &resumeState{counter: wordCounter, checkpoint: checkpoint, statePath: opts.state}
+	}
+
+	// workers count each document straight into the counters
+	sink := processor.SourceSinkFunc(func(source string, wordFrequencies map[string]int) {
+		if resume != nil {
+			resume.accept(source, wordFrequencies)
+		} else {
+			wordCounter.Accept(wordFrequencies)
+		}
+		docCounter.AddDocument(wordFrequencies)
+		if hosts != nil {
+			hosts.add(source, wordFrequencies)
+		}
+	})
+	pool := processor.NewWorkerPoolWithOptions(wordBank, opts.workers, processor.WorkerPoolOptions{Weights: opts.weights, Sink: sink, Logger: logger})
+	pool.StartWithContext(ctx)
 
 	// initialize the struct to fetch the urls
-	f := fetcher.NewFetcher()
+	fetcherConfig := fetcher.DefaultConfig()
+	fetcherConfig.MinContentWords = opts.minWords
+	fetcherConfig.PerURLTimeout = opts.urlTimeout
+	fetcherConfig.MaxTotalRetries = opts.retryBudget
+	fetcherConfig.Logger = logger
+	fetcherConfig.OnProgress = newProgressReporter(len(urls), !opts.noProgress && isTerminal(os.Stdout))
+	f := fetcher.NewFetcherWithConfig(fetcherConfig)
+	if opts.metrics != "" {
+		go func() {
+			if err := f.ServeMetrics(opts.metrics); err != nil {
+				logger.Error("Metrics server stopped", "err", err)
+			}
+		}()
+	}
+
+	var textStats processor.Stats
+	var numbers *processor.NumericCounter
+	if opts.numbers {
+		numbers = processor.NewNumericCounter()
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(2)
-	wordCounter := processor.NewSafeWordCounter()
 
 	done := make(chan struct{})
 	go func() {
 		<-sigChan
-		log.Println("\nReceived interrupt signal. Starting graceful shutdown...")
+		logger.Info("Received interrupt signal, starting graceful shutdown")
 		cancel()
 	}()
 
@@ -71,114 +366,378 @@ func main() {
 	// 1. fetch urls
 	go func() {
 		defer wg.Done()
-		defer pool.Close()
+		defer func() {
+			if err := pool.CloseWithTimeout(poolCloseTimeout); err != nil {
+				logger.Error("Failed to shut down worker pool", "err", err)
+			}
+		}()
 
 		results := f.FetchURLs(ctx, urls)
 		for result := range results {
 			select {
 			case <-ctx.Done():
-				log.Println("Context cancelled, stopping URL processing")
+				logger.Info("Context cancelled, stopping URL processing")
 				return
 			default:
-				pool.Submit(result.Content)
-				if err := bar.Add(1); err != nil {
-					log.Printf("Failed to update progress bar: %v", err)
+				if opts.textStats && result.Error == "" {
+					textStats = textStats.Add(processor.TextStats(result.Content))
+				}
+				if numbers != nil && result.Error == "" {
+					numbers.Add(result.Content)
+				}
+				if urlRecords != nil {
+					if err := writeURLRecord(urlRecords, result); err != nil {
+						logger.Error("Failed to write URL log", "err", err)
+					}
+				}
+				if err := submitResult(pool, result, opts.weights); err != nil {
+					logger.Warn("Stopping URL processing", "err", err)
+					return
 				}
 			}
 		}
 	}()
 
-	// 2. collect results
+	if opts.leaders > 0 {
+		go logLeaders(wordCounter, opts.top, opts.leaders, done)
+	}
+	if resume != nil {
+		go resume.saveEvery(checkpointFlush, done)
+	}
+	if opts.snapshot != "" {
+		go writeSnapshots(opts.snapshot, wordCounter, opts.top, opts.snapshotEvery, done)
+	}
+
+	// 2. report documents that failed processing
 	go func() {
 		defer wg.Done()
 
-		for wordFrequencies := range pool.Results() {
-			select {
-			case <-ctx.Done():
-				log.Println("Context cancelled, stopping result collection")
-				return
-			default:
-				for word, frequency := range wordFrequencies {
-					wordCounter.Increment(word, frequency)
-				}
-			}
+		for err := range pool.Errors() {
+			logger.Warn("Failed to process document", "err", err)
 		}
 	}()
 
 	<-done
 
-	finalWordCounts := wordCounter.GetTopWordCounts(10) // get the top 10 words
-	printFinalResults(startTime, finalWordCounts, f)
+	if opts.failures != "" {
+		if err := saveFailures(opts.failures, f.Failures()); err != nil {
+			logger.Error("Failed to save failed URLs", "path", opts.failures, "err", err)
+		}
+	}
+
+	if resume != nil {
+		if err := resume.save(); err != nil {
+			logger.Error("Failed to save word counts", "err", err)
+		}
+	} else if opts.state != "" {
+		if err := wordCounter.SaveCounts(opts.state); err != nil {
+			logger.Error("Failed to save word counts", "err", err)
+		}
+	}
+
+	finalWordCounts := wordCounter.GetTopWords(opts.top)
+	finalDocCounts := docCounter.GetTopWords(opts.top)
+	finalTFIDF := docCounter.TopTFIDF(opts.top)
+	output := newFinalResults(startTime, finalWordCounts, finalDocCounts, finalTFIDF, docCounter.Documents(), pool.PoolMetrics(), f)
+	if opts.histogram {
+		output.LengthHistogram = wordCounter.LengthHistogram()
+	}
+	if hosts != nil {
+		output.Hosts = hosts.top(opts.top)
+	}
+	if opts.textStats {
+		output.TextStats = &textStats
+	}
+	if numbers != nil {
+		output.TopNumbers = numbers.TopNumbers(opts.top)
+	}
+
+	if opts.output != "" {
+		if err := saveFinalResults(opts.output, opts.format, output); err != nil {
+			logger.Error("Failed to save results", "path", opts.output, "err", err)
+		} else {
+			logger.Info("Results saved", "path", opts.output)
+		}
+	}
+	if opts.output == "" || !opts.quiet {
+		printFinalResults(output, opts.format)
+	}
+}
+
+// submitResult queues a fetched page, split into its parts when weights are
+// set so title and body words can count differently. Failed fetches and
+// empty pages such as 404s aren't queued, so they don't count as documents.
+func submitResult(pool *processor.WorkerPool, result fetcher.FetchResult, weights processor.Weights) error {
+	if result.Error != "" || result.Content == "" {
+		return nil
+	}
+	if weights == (processor.Weights{}) || result.Parsed == nil {
+		return pool.SubmitFrom(result.URL, result.Content)
+	}
+
+	return pool.SubmitDocument(processor.Document{
+		Title:   result.Parsed.Title,
+		Subhead: result.Parsed.Subhead,
+		Body:    result.Parsed.Body,
+		Source:  result.URL,
+	})
 }
 
-func getInputFilename() string {
+// logLeaders logs the current top words every interval until done closes.
+func logLeaders(counter *processor.SafeWordCounter, top int, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if leaders := counter.SnapshotTop(top); len(leaders) > 0 {
+				logger.Info("Current leaders", "words", formatLeaders(leaders))
+			}
+		}
+	}
+}
+
+func formatLeaders(leaders []processor.WordCount) string {
+	parts := make([]string, len(leaders))
+	for i, wc := range leaders {
+		parts[i] = fmt.Sprintf("%s=%d", wc.Word, wc.Count)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// newProgressReporter draws a progress bar, or when showBar is false logs a
+// line every 5% so redirected output and CI logs stay readable.
+func newProgressReporter(total int, showBar bool) func(done, total int) {
+	if showBar {
+		bar := progressbar.Default(int64(total), "Processing URLs")
+		return func(done, _ int) {
+			if err := bar.Set(done); err != nil {
+				logger.Error("Failed to update progress bar", "err", err)
+			}
+		}
+	}
+
+	step := max(total/20, 1)
+	return func(done, total int) {
+		if done%step == 0 || done == total {
+			logger.Info("Processed URLs", "done", done, "total", total, "percent", fmt.Sprintf("%.0f%%", float64(done)*100/float64(total)))
+		}
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func getInputFilename() (string, error) {
 	fmt.Println("Select the number of URLs to process:")
 	fmt.Println("1. 1,000 URLs")
 	fmt.Println("2. 10,000 URLs")
 	fmt.Println("3. 40,000 URLs")
+	fmt.Println("4. Custom URL file")
 
 	var choice int
-	fmt.Print("Enter your choice (1, 2, or 3): ")
+	fmt.Print("Enter your choice (1, 2, 3, or 4): ")
 	if _, err := fmt.Scan(&choice); err != nil {
-		log.Fatalf("Failed to read choice: %v", err)
+		return "", fmt.Errorf("failed to read choice: %w", err)
 	}
 
 	switch choice {
-	case 1:
-		return "data/input/1k-endg-urls.txt"
-	case 2:
-		return "data/input/10k-endg-urls.txt"
-	case 3:
-		return "data/input/40k-endg-urls.txt"
+	case 1, 2, 3:
+		return bundledInputs[choice-1], nil
+	case 4:
+		var path string
+		fmt.Print("Enter the path to the URL file: ")
+		if _, err := fmt.Scan(&path); err != nil {
+			return "", fmt.Errorf("failed to read path: %w", err)
+		}
+		if err := validateInputFile(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	default:
+		return "", fmt.Errorf("invalid choice %d, please select 1, 2, 3, or 4", choice)
+	}
+}
+
+func validateInputFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("invalid input file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("invalid input file: %s is a directory", path)
+	}
+	return nil
+}
+
+// printDryRun reports how many URLs a run would fetch and lists the first few.
+func printDryRun(w io.Writer, urls []string) {
+	fmt.Fprintf(w, "%d URLs would be fetched\n", len(urls))
+	for _, url := range urls[:min(dryRunSample, len(urls))] {
+		fmt.Fprintf(w, "  %s\n", url)
+	}
+	if len(urls) > dryRunSample {
+		fmt.Fprintf(w, "  ... and %d more\n", len(urls)-dryRunSample)
+	}
+}
+
+// runDebug fetches the -debug URL and prints what was extracted from it.
+func runDebug(w io.Writer, opts options) error {
+	var wordBank *processor.ValidWordBank
+	if !opts.noBank {
+		var err error
+		if wordBank, err = initializeWordBank(wordBankPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	result := fetcher.NewFetcher().FetchSingle(ctx, opts.debug)
+	if result.Error != "" {
+		return errors.New(result.Error)
+	}
+	printDebug(w, result, wordBank)
+	return nil
+}
+
+// printDebug shows a page's extracted text and the words that would be
+// counted from it, to explain pages that yield few or no counts.
+func printDebug(w io.Writer, result fetcher.FetchResult, wordBank *processor.ValidWordBank) {
+	fmt.Fprintf(w, "URL: %s\n", result.URL)
+	if result.FinalURL != "" && result.FinalURL != result.URL {
+		fmt.Fprintf(w, "Redirected to: %s\n", result.FinalURL)
+	}
+	fmt.Fprintf(w, "\nExtracted text (%d words):\n%s\n", result.WordCount, result.Content)
+
+	words := processor.Tokenize(result.Content, wordBank, processor.WordOptions{})
+	fmt.Fprintf(w, "\nCounted words (%d):\n%s\n", len(words), strings.Join(words, " "))
+}
+
+// loadURLs picks the reader by extension, ignoring a trailing .gz, and
+// otherwise expects one URL per line.
+func loadURLs(path string, opts options) ([]string, error) {
+	switch filepath.Ext(strings.TrimSuffix(path, ".gz")) {
+	case ".csv":
+		return fetcher.FetchURLsFromCSV(path, opts.csvColumn)
+	case ".json":
+		return fetcher.FetchURLsFromJSON(path, opts.jsonField)
 	default:
-		log.Fatalf("Invalid choice. Please select 1, 2, or 3")
-		return ""
+		return fetcher.FetchFromFile(path)
 	}
 }
 
-func initializeWordBank() (*processor.ValidWordBank, error) {
-	rawWords, err := fetcher.FetchFromFile("data/input/words.txt")
+// initializeWordBank loads the word bank from path. Errors wrap
+// fs.ErrNotExist when the file is missing, so callers can fall back to
+// counting every word.
+func initializeWordBank(path string) (*processor.ValidWordBank, error) {
+	wordBank, err := processor.ProcessValidWordBankFromFiles(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load bank of words: %v", err)
+		return nil, fmt.Errorf("failed to load bank of words: %w", err)
 	}
 
-	wordBank := processor.ProcessValidWordBank(rawWords)
-	if err := fetcher.SaveToFile("data/output/valid_word_bank.txt", wordBank.GetWords()); err != nil {
+	if err := fetcher.SaveToFile(filepath.Join(outputDir, "valid_word_bank.txt"), wordBank.GetWords()); err != nil {
 		return nil, fmt.Errorf("failed to save word bank to file: %v", err)
 	}
 
 	return wordBank, nil
 }
 
-func printFinalResults(startTime time.Time, wordCounts []map[string]int, f *fetcher.Fetcher) {
+type finalResults struct {
+	TopWords         []processor.WordCount `json:"top_words"`
+	TopDocumentWords []processor.WordCount `json:"top_document_words"`
+	TopTFIDF         []processor.WordScore `json:"top_tfidf"`
+	LengthHistogram  map[int]int           `json:"length_histogram,omitempty"`
+	SlowestURLs      []slowURL             `json:"slowest_urls,omitempty"`
+	Hosts            []hostWords           `json:"hosts,omitempty"`
+	TextStats        *processor.Stats      `json:"text_stats,omitempty"`
+	TopNumbers       []processor.WordCount `json:"top_numbers,omitempty"`
+	Metrics          resultMetrics         `json:"metrics"`
+}
+
+type slowURL struct {
+	URL     string  `json:"url"`
+	Seconds float64 `json:"seconds"`
+}
+
+type resultMetrics struct {
+	DurationSeconds float64 `json:"duration_seconds"`
+	Processed       int64   `json:"processed"`
+	Errors          int64   `json:"errors"`
+	RateLimited     int64   `json:"rate_limited"`
+	Skipped         int64   `json:"skipped"`
+	Documents       int     `json:"documents"`
+	JobsProcessed   int64   `json:"jobs_processed"`
+	AvgProcessingMs float64 `json:"avg_processing_ms"`
+	WordsPerSecond  float64 `json:"words_per_second"`
+
+	// RetriesRemaining is only set when -retry-budget caps retries.
+	RetriesRemaining *int64 `json:"retries_remaining,omitempty"`
+	// StatusCodes maps each HTTP status code to how many responses had it.
+	StatusCodes map[int]int64 `json:"status_codes,omitempty"`
+	// StopReason says whether the run completed, timed out or was cancelled.
+	StopReason string `json:"stop_reason,omitempty"`
+}
+
+func newFinalResults(startTime time.Time, wordCounts, docCounts []processor.WordCount, tfidf []processor.WordScore, documents int, poolMetrics processor.PoolMetrics, f *fetcher.Fetcher) finalResults {
 	metrics := f.GetMetrics()
-	output := struct {
-		TopWords []map[string]int `json:"top_words"`
-		Metrics  struct {
-			DurationSeconds float64 `json:"duration_seconds"`
-			Processed       int64   `json:"processed"`
-			Errors          int64   `json:"errors"`
-			RateLimited     int64   `json:"rate_limited"`
-		} `json:"metrics"`
-	}{
-		TopWords: wordCounts,
-		Metrics: struct {
-			DurationSeconds float64 `json:"duration_seconds"`
-			Processed       int64   `json:"processed"`
-			Errors          int64   `json:"errors"`
-			RateLimited     int64   `json:"rate_limited"`
-		}{
+	var retriesRemaining *int64
+	if metrics.RetriesRemaining >= 0 {
+		retriesRemaining = &metrics.RetriesRemaining
+	}
+
+	var slowest []slowURL
+	for _, s := range f.SlowestURLs() {
+		slowest = append(slowest, slowURL{URL: s.URL, Seconds: s.Latency.Seconds()})
+	}
+
+	return finalResults{
+		TopWords:         wordCounts,
+		TopDocumentWords: docCounts,
+		TopTFIDF:         tfidf,
+		SlowestURLs:      slowest,
+		Metrics: resultMetrics{
 			DurationSeconds: time.Since(startTime).Seconds(),
 			Processed:       metrics.Processed,
 			Errors:          metrics.Errors,
 			RateLimited:     metrics.RateLimited,
+			Skipped:         metrics.Skipped,
+			Documents:       documents,
+			JobsProcessed:   poolMetrics.JobsProcessed,
+			AvgProcessingMs: float64(poolMetrics.AvgProcessingTime) / float64(time.Millisecond),
+			WordsPerSecond:  poolMetrics.WordsPerSecond(),
+
+			RetriesRemaining: retriesRemaining,
+			StatusCodes:      metrics.StatusCodes,
+			StopReason:       metrics.StopReason,
 		},
 	}
+}
 
-	jsonOutput, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		log.Fatalf("Failed to marshal JSON: %v", err)
+// printFinalResults writes the results to stdout. Only the table gets a
+// heading, so JSON and CSV output can be redirected straight to a file.
+func printFinalResults(output finalResults, format string) {
+	if format == formatTable {
+		fmt.Println("\nFinal Results:")
+	}
+	if err := FormatResults(os.Stdout, format, output); err != nil {
+		fatal("Failed to format results", "err", err)
+	}
+}
+
+func saveFinalResults(path, format string, output finalResults) error {
+	var buf bytes.Buffer
+	if err := FormatResults(&buf, format, output); err != nil {
+		return err
 	}
-	fmt.Println("\nFinal Results:")
-	fmt.Println(string(jsonOutput))
+	return fetcher.SaveToFile(path, buf.String())
 }
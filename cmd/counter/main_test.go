@@ -3,21 +3,34 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/shuaibbapputty/word-counter/internal/logging"
+	"github.com/shuaibbapputty/word-counter/internal/processor"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetInputFilename(t *testing.T) {
+	customFile := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(customFile, []byte("http://example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write custom file: %v", err)
+	}
+
 	tests := []struct {
 		name     string
 		input    string
 		expected string
+		wantErr  bool
 	}{
 		{
 			name:     "Choice 1",
@@ -34,6 +47,21 @@ func TestGetInputFilename(t *testing.T) {
 			input:    "3\n",
 			expected: "data/input/40k-endg-urls.txt",
 		},
+		{
+			name:     "Custom file",
+			input:    "4\n" + customFile + "\n",
+			expected: customFile,
+		},
+		{
+			name:    "Missing custom file",
+			input:   "4\n" + filepath.Join(t.TempDir(), "missing.txt") + "\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid choice",
+			input:   "7\n",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -47,27 +75,361 @@ func TestGetInputFilename(t *testing.T) {
 			}
 			w.Close()
 
-			filename := getInputFilename()
-			assert.Equal(t, tt.expected, filename)
+			filename, err := getInputFilename()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, filename)
+			}
 
 			os.Stdin = oldStdin
 		})
 	}
 }
 
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    options
+		wantErr bool
+	}{
+		{
+			name: "no flags",
+			args: nil,
+			want: options{workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatTable, snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "all flags",
+			args: []string{"-input", "mylist.txt", "-workers", "8", "-top", "25", "-timeout", "10m", "-min-words", "50", "-leaders", "5s", "-url-timeout", "1m", "-retry-budget", "500"},
+			want: options{input: "mylist.txt", workers: 8, top: 25, timeout: 10 * time.Minute, format: formatJSON, minWords: 50, leaders: 5 * time.Second, urlTimeout: time.Minute, retryBudget: 500, snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "output and state files",
+			args: []string{"-input", "mylist.txt", "-output", "results.json", "-quiet", "-state", "counts.json", "-no-bank", "-dry-run", "-ndjson", "urls.ndjson", "-metrics-addr", ":9090"},
+			want: options{input: "mylist.txt", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, output: "results.json", quiet: true, format: formatJSON, state: "counts.json", noBank: true, dryRun: true, ndjson: "urls.ndjson", metrics: ":9090", snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "no progress bar, resume, histogram and numbers",
+			args: []string{"-input", "mylist.txt", "-no-progress", "-resume", "done.txt", "-state", "counts.json", "-histogram", "-numbers"},
+			want: options{input: "mylist.txt", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, noProgress: true, resume: "done.txt", state: "counts.json", histogram: true, numbers: true, snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "csv and json inputs",
+			args: []string{"-input", "export.csv", "-csv-column", "2", "-json-field", "data.urls"},
+			want: options{input: "export.csv", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, csvColumn: 2, jsonField: "data.urls", snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "weights",
+			args: []string{"-input", "mylist.txt", "-weights", "3, 2,1"},
+			want: options{input: "mylist.txt", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, weights: processor.Weights{Title: 3, Subhead: 2, Body: 1}, snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "snapshot",
+			args: []string{"-input", "mylist.txt", "-snapshot", "partial.json", "-snapshot-every", "1m"},
+			want: options{input: "mylist.txt", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, snapshot: "partial.json", snapshotEvery: time.Minute},
+		},
+		{
+			name:    "resume without state",
+			args:    []string{"-input", "mylist.txt", "-resume", "done.txt"},
+			wantErr: true,
+		},
+		{
+			name:    "zero snapshot interval",
+			args:    []string{"-input", "mylist.txt", "-snapshot-every", "0s"},
+			wantErr: true,
+		},
+		{
+			name: "log level",
+			args: []string{"-input", "mylist.txt", "-log-level", "warn"},
+			want: options{input: "mylist.txt", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, snapshotEvery: defaultSnapshotInterval, logLevel: slog.LevelWarn},
+		},
+		{
+			name:    "unknown log level",
+			args:    []string{"-input", "mylist.txt", "-log-level", "loud"},
+			wantErr: true,
+		},
+		{
+			name:    "too few weights",
+			args:    []string{"-input", "mylist.txt", "-weights", "3,2"},
+			wantErr: true,
+		},
+		{
+			name:    "zero weight",
+			args:    []string{"-input", "mylist.txt", "-weights", "3,0,1"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"-input", "mylist.txt", "-format", "xml"},
+			wantErr: true,
+		},
+		{
+			name:    "flags without input",
+			args:    []string{"-top", "25"},
+			wantErr: true,
+		},
+		{
+			name: "selftest without input",
+			args: []string{"-selftest"},
+			want: options{workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, selfTest: true, snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "debug without input",
+			args: []string{"-debug", "http://example.com/page"},
+			want: options{workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, debug: "http://example.com/page", snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "invalid workers and timeout fall back to defaults",
+			args: []string{"-input", "mylist.txt", "-workers", "0", "-timeout", "-1s"},
+			want: options{input: "mylist.txt", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name: "limit",
+			args: []string{"-input", "mylist.txt", "-limit", "100"},
+			want: options{input: "mylist.txt", workers: defaultNumWorkers, top: defaultTopN, timeout: executionTimeout, format: formatJSON, limit: 100, snapshotEvery: defaultSnapshotInterval},
+		},
+		{
+			name:    "negative limit",
+			args:    []string{"-input", "mylist.txt", "-limit", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "zero top",
+			args:    []string{"-input", "mylist.txt", "-top", "0"},
+			wantErr: true,
+		},
+		{
+			name:    "negative top",
+			args:    []string{"-input", "mylist.txt", "-top", "-5"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-bogus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOptions(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadURLs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"urls.txt":  "http://example.com/txt\n",
+		"urls.csv":  "name,url\ntxt,http://example.com/csv\n",
+		"urls.json": `{"urls": ["http://example.com/json"]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	opts := options{csvColumn: 1, jsonField: "urls"}
+	for name, want := range map[string]string{"urls.txt": "http://example.com/txt", "urls.csv": "http://example.com/csv", "urls.json": "http://example.com/json"} {
+		urls, err := loadURLs(filepath.Join(dir, name), opts)
+		assert.NoError(t, err, name)
+		assert.Equal(t, []string{want}, urls, name)
+	}
+}
+
+func TestInitializeWordBankMissing(t *testing.T) {
+	_, err := initializeWordBank(filepath.Join(t.TempDir(), "words.txt"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestPrintDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	printDryRun(&buf, []string{"http://example.com/1", "http://example.com/2"})
+	assert.Equal(t, "2 URLs would be fetched\n  http://example.com/1\n  http://example.com/2\n", buf.String())
+
+	urls := make([]string, dryRunSample+5)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("http://example.com/%d", i)
+	}
+	buf.Reset()
+	printDryRun(&buf, urls)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, dryRunSample+2)
+	assert.Equal(t, "... and 5 more", strings.TrimSpace(lines[len(lines)-1]))
+}
+
+func TestPrintDebug(t *testing.T) {
+	result := fetcher.FetchResult{
+		URL:       "http://example.com/old",
+		FinalURL:  "http://example.com/new",
+		Content:   "Hello there, big world",
+		WordCount: 4,
+	}
+	wordBank := processor.ProcessValidWordBank([]string{"hello", "world"})
+
+	var buf bytes.Buffer
+	printDebug(&buf, result, wordBank)
+	assert.Equal(t, `URL: http://example.com/old
+Redirected to: http://example.com/new
+
+Extracted text (4 words):
+Hello there, big world
+
+Counted words (2):
+hello world
+`, buf.String())
+}
+
+func TestSubmitResult(t *testing.T) {
+	wordBank := processor.ProcessValidWordBank([]string{"news", "story"})
+	result := fetcher.FetchResult{
+		Content: "News story",
+		Parsed:  &fetcher.ParsedDocument{Title: "News", Body: "story"},
+	}
+
+	tests := []struct {
+		name    string
+		weights processor.Weights
+		want    map[string]int
+	}{
+		{name: "unweighted", want: map[string]int{"news": 1, "story": 1}},
+		{name: "weighted title", weights: processor.Weights{Title: 4}, want: map[string]int{"news": 4, "story": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := processor.NewWorkerPoolWithOptions(wordBank, 1, processor.WorkerPoolOptions{Weights: tt.weights})
+			pool.Start()
+			assert.NoError(t, submitResult(pool, result, tt.weights))
+			pool.Close()
+
+			assert.Equal(t, tt.want, <-pool.Results())
+		})
+	}
+}
+
+func TestSubmitResultSkipsErrors(t *testing.T) {
+	docCounter := processor.NewDocumentFrequencyCounter()
+	pool := processor.NewWorkerPoolWithOptions(nil, 1, processor.WorkerPoolOptions{Sink: processor.ResultSinkFunc(docCounter.AddDocument)})
+	pool.Start()
+	assert.NoError(t, submitResult(pool, fetcher.FetchResult{Content: "launch news"}, processor.Weights{}))
+	assert.NoError(t, submitResult(pool, fetcher.FetchResult{Error: "unexpected status: 500"}, processor.Weights{}))
+	assert.NoError(t, submitResult(pool, fetcher.FetchResult{URL: "http://example.com/missing"}, processor.Weights{}))
+	pool.Close()
+
+	assert.Equal(t, 1, docCounter.Documents(), "404s come back without an error but with no content")
+}
+
+func TestSubmitResultTFIDFIgnoresErrors(t *testing.T) {
+	docCounter := processor.NewDocumentFrequencyCounter()
+	pool := processor.NewWorkerPoolWithOptions(nil, 1, processor.WorkerPoolOptions{Sink: processor.ResultSinkFunc(docCounter.AddDocument)})
+	pool.Start()
+	results := []fetcher.FetchResult{
+		{Content: "launch rocket"},
+		{Content: "launch garden"},
+		{Error: "unexpected status: 500"},
+		{URL: "http://example.com/missing"},
+	}
+	for _, result := range results {
+		assert.NoError(t, submitResult(pool, result, processor.Weights{}))
+	}
+	pool.Close()
+
+	scores := make(map[string]float64)
+	for _, score := range docCounter.TopTFIDF(3) {
+		scores[score.Word] = score.Score
+	}
+	assert.Zero(t, scores["launch"], "a word in every article isn't distinctive")
+	assert.InDelta(t, math.Log(2), scores["rocket"], 1e-9)
+}
+
+// captureLogs sends the counter's logs to the returned buffer until t ends.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	saved := logger
+	logger = logging.New(&buf, slog.LevelInfo)
+	t.Cleanup(func() { logger = saved })
+	return &buf
+}
+
+func TestNewProgressReporterText(t *testing.T) {
+	buf := captureLogs(t)
+
+	report := newProgressReporter(40, false)
+	for done := 1; done <= 40; done++ {
+		report(done, 40)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 20)
+	assert.Contains(t, lines[0], `level=INFO msg="Processed URLs" done=2 total=40 percent=5%`)
+	assert.Contains(t, lines[19], `level=INFO msg="Processed URLs" done=40 total=40 percent=100%`)
+}
+
+func TestLogLeaders(t *testing.T) {
+	buf := captureLogs(t)
+
+	counter := processor.NewSafeWordCounter()
+	counter.Increment("hello", 3)
+	counter.Increment("world", 1)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		logLeaders(counter, 2, 5*time.Millisecond, done)
+		close(stopped)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(done)
+	<-stopped
+
+	assert.Contains(t, buf.String(), `level=INFO msg="Current leaders" words="hello=3, world=1"`)
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.False(t, isTerminal(f))
+}
+
 func TestPrintFinalResults(t *testing.T) {
 	old := os.Stdout
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
 	startTime := time.Now().Add(-5 * time.Second) // 5 seconds ago
-	wordCounts := []map[string]int{
-		{"test": 10},
-		{"example": 5},
+	wordCounts := []processor.WordCount{
+		{Word: "test", Count: 10},
+		{Word: "example", Count: 5},
+	}
+	docCounts := []processor.WordCount{
+		{Word: "example", Count: 3},
+		{Word: "test", Count: 2},
+	}
+	tfidf := []processor.WordScore{
+		{Word: "example", Score: 1.5},
+	}
+	poolMetrics := processor.PoolMetrics{
+		JobsProcessed:  4,
+		WordsProcessed: 1000,
+		ProcessingTime: 4 * time.Second,
+		ElapsedTime:    2 * time.Second,
 	}
 	f := fetcher.NewFetcher()
 
-	printFinalResults(startTime, wordCounts, f)
+	printFinalResults(newFinalResults(startTime, wordCounts, docCounts, tfidf, 4, poolMetrics, f), formatJSON)
 
 	w.Close()
 	os.Stdout = old
@@ -79,28 +441,65 @@ func TestPrintFinalResults(t *testing.T) {
 	}
 	output := buf.String()
 
-	var result struct {
-		TopWords []map[string]int `json:"top_words"`
-		Metrics  struct {
-			DurationSeconds float64 `json:"duration_seconds"`
-			Processed       int64   `json:"processed"`
-			Errors          int64   `json:"errors"`
-			RateLimited     int64   `json:"rate_limited"`
-		} `json:"metrics"`
-	}
+	var result finalResults
 
-	jsonStr := strings.TrimPrefix(output, "\nFinal Results:\n")
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
 		t.Errorf("Failed to parse JSON output: %v", err)
 	}
 
 	if len(result.TopWords) != 2 {
 		t.Errorf("Expected 2 top words, got %d", len(result.TopWords))
 	}
-	if result.TopWords[0]["test"] != 10 {
-		t.Errorf("Expected count 10 for 'test', got %d", result.TopWords[0]["test"])
+	if result.TopWords[0] != (processor.WordCount{Word: "test", Count: 10}) {
+		t.Errorf("Expected count 10 for 'test', got %v", result.TopWords[0])
+	}
+	if result.TopDocumentWords[0] != (processor.WordCount{Word: "example", Count: 3}) {
+		t.Errorf("Expected document count 3 for 'example', got %v", result.TopDocumentWords[0])
+	}
+	if len(result.TopTFIDF) != 1 || result.TopTFIDF[0].Word != "example" {
+		t.Errorf("Expected TF-IDF leader 'example', got %v", result.TopTFIDF)
+	}
+	if result.Metrics.Documents != 4 {
+		t.Errorf("Expected 4 documents, got %d", result.Metrics.Documents)
+	}
+	if result.Metrics.JobsProcessed != 4 {
+		t.Errorf("Expected 4 jobs processed, got %d", result.Metrics.JobsProcessed)
+	}
+	if result.Metrics.WordsPerSecond != 500 {
+		t.Errorf("Expected 500 words per second, got %f", result.Metrics.WordsPerSecond)
+	}
+	if result.Metrics.RetriesRemaining != nil {
+		t.Errorf("Expected no retry budget, got %d", *result.Metrics.RetriesRemaining)
 	}
 	if result.Metrics.DurationSeconds < 4.9 || result.Metrics.DurationSeconds > 5.1 {
 		t.Errorf("Expected duration around 5 seconds, got %f", result.Metrics.DurationSeconds)
 	}
 }
+
+func TestSaveFinalResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	output := finalResults{
+		TopWords:        []processor.WordCount{{Word: "test", Count: 10}},
+		LengthHistogram: map[int]int{4: 10},
+		Metrics:         resultMetrics{Processed: 3},
+	}
+
+	assert.NoError(t, saveFinalResults(path, formatJSON, output))
+
+	saved, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var result finalResults
+	assert.NoError(t, json.Unmarshal(saved, &result))
+	assert.Equal(t, output.TopWords, result.TopWords)
+	assert.Equal(t, output.LengthHistogram, result.LengthHistogram)
+	assert.Equal(t, int64(3), result.Metrics.Processed)
+
+	assert.Error(t, saveFinalResults(filepath.Join(t.TempDir(), "missing", "results.json"), formatJSON, output))
+
+	csvPath := filepath.Join(t.TempDir(), "results.csv")
+	assert.NoError(t, saveFinalResults(csvPath, formatCSV, output))
+	saved, err = os.ReadFile(csvPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "word,count\ntest,10\n", string(saved))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+)
+
+// resumeState keeps the -resume checkpoint in step with the -state counts. A
+// URL is written to the checkpoint only once the counts including it have
+// been saved, so a resumed run never skips a URL whose counts were lost.
+type resumeState struct {
+	mu         sync.Mutex
+	counter    *processor.SafeWordCounter
+	checkpoint *fetcher.Checkpoint
+	statePath  string
+	pending    []string // URLs counted since the last save
+}
+
+// accept adds a document's counts and queues its URL for the checkpoint.
+func (r *resumeState) accept(source string, wordCounts map[string]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counter.Accept(wordCounts)
+	r.pending = append(r.pending, source)
+}
+
+// save writes the counts, then records and flushes the URLs they cover.
+func (r *resumeState) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.counter.SaveCounts(r.statePath); err != nil {
+		return err
+	}
+	for _, url := range r.pending {
+		if err := r.checkpoint.Record(url); err != nil {
+			return err
+		}
+	}
+	r.pending = r.pending[:0]
+	return r.checkpoint.Flush()
+}
+
+// saveEvery saves every interval until done closes.
+func (r *resumeState) saveEvery(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := r.save(); err != nil {
+				logger.Error("Failed to save resume state", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeState(t *testing.T) {
+	dir := t.TempDir()
+	checkpointPath := filepath.Join(dir, "done.txt")
+	statePath := filepath.Join(dir, "counts.json")
+
+	checkpoint, err := fetcher.OpenCheckpoint(checkpointPath, 0)
+	require.NoError(t, err)
+	resume := &resumeState{counter: processor.NewSafeWordCounter(), checkpoint: checkpoint, statePath: statePath}
+
+	resume.accept("http://example.com/1", map[string]int{"launch": 2})
+	completed, err := fetcher.LoadCheckpoint(checkpointPath)
+	require.NoError(t, err)
+	assert.Empty(t, completed, "URLs aren't checkpointed before their counts are saved")
+
+	require.NoError(t, resume.save())
+	resume.accept("http://example.com/2", map[string]int{"launch": 1})
+
+	completed, err = fetcher.LoadCheckpoint(checkpointPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"http://example.com/1": {}}, completed)
+	counts, err := processor.LoadCounts(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, counts.GetCount("launch"))
+
+	resume.statePath = filepath.Join(dir, "missing", "counts.json")
+	assert.Error(t, resume.save())
+	require.NoError(t, checkpoint.Close())
+	completed, err = fetcher.LoadCheckpoint(checkpointPath)
+	require.NoError(t, err)
+	assert.NotContains(t, completed, "http://example.com/2", "a URL whose counts failed to save stays unrecorded")
+}
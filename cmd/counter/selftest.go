@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+)
+
+// selfTestFetchTimeout bounds the test fetch, so -selftest fails quickly on
+// a hanging host.
+const selfTestFetchTimeout = 30 * time.Second
+
+// selfCheck is one step of -selftest. run returns a short detail on success.
+type selfCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// selfTestChecks checks the input file, or the bundled ones without -input,
+// the word bank, a fetch of the first input URL and that the output
+// directory is writable. It doesn't write any output.
+func selfTestChecks(opts options, bankPath string) []selfCheck {
+	inputs := []string{opts.input}
+	if opts.input == "" {
+		inputs = bundledInputs
+	}
+
+	var urls []string
+	return []selfCheck{
+		{"input file", func(context.Context) (string, error) {
+			details := make([]string, 0, len(inputs))
+			for _, input := range inputs {
+				if err := validateInputFile(input); err != nil {
+					return "", err
+				}
+				loaded, err := loadURLs(input, opts)
+				if err != nil {
+					return "", err
+				}
+				if urls == nil {
+					urls = loaded
+				}
+				details = append(details, fmt.Sprintf("%d URLs in %s", len(loaded), input))
+			}
+			return strings.Join(details, ", "), nil
+		}},
+		{"word bank", func(context.Context) (string, error) {
+			if opts.noBank {
+				return "skipped with -no-bank", nil
+			}
+			wordBank, err := processor.ProcessValidWordBankFromFiles(bankPath)
+			if err != nil {
+				return "", fmt.Errorf("failed to load bank of words: %w", err)
+			}
+			return fmt.Sprintf("%d words in %s", wordBank.Len(), bankPath), nil
+		}},
+		{"test fetch", func(ctx context.Context) (string, error) {
+			if len(urls) == 0 {
+				return "", errors.New("no input URL to fetch")
+			}
+			ctx, cancel := context.WithTimeout(ctx, selfTestFetchTimeout)
+			defer cancel()
+
+			result := fetcher.NewFetcher().FetchSingle(ctx, urls[0])
+			if result.Error != "" {
+				return "", fmt.Errorf("%s: %s", urls[0], result.Error)
+			}
+			return fmt.Sprintf("%d words from %s", result.WordCount, urls[0]), nil
+		}},
+		{"output directory", func(context.Context) (string, error) {
+			dir := outputDir
+			if opts.output != "" {
+				dir = filepath.Dir(opts.output)
+			}
+			return dir, checkWritable(dir)
+		}},
+	}
+}
+
+// checkWritable creates dir if needed and writes a temporary file in it.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".selftest-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// runSelfTest runs the checks in order, printing a line for each and a
+// summary, and reports whether all passed.
+func runSelfTest(ctx context.Context, w io.Writer, checks []selfCheck) bool {
+	passed := 0
+	for _, check := range checks {
+		detail, err := check.run(ctx)
+		if err != nil {
+			fmt.Fprintf(w, "FAIL  %s: %v\n", check.name, err)
+			continue
+		}
+		passed++
+		fmt.Fprintf(w, "PASS  %s: %s\n", check.name, detail)
+	}
+	fmt.Fprintf(w, "%d of %d checks passed\n", passed, len(checks))
+	return passed == len(checks)
+}
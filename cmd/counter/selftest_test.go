@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	checks := []selfCheck{
+		{"first", func(context.Context) (string, error) { return "fine", nil }},
+		{"second", func(context.Context) (string, error) { return "", errors.New("broken") }},
+	}
+
+	var buf bytes.Buffer
+	assert.False(t, runSelfTest(context.Background(), &buf, checks))
+	assert.Equal(t, "PASS  first: fine\nFAIL  second: broken\n1 of 2 checks passed\n", buf.String())
+
+	buf.Reset()
+	assert.True(t, runSelfTest(context.Background(), &buf, checks[:1]))
+}
+
+func TestSelfTestChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>three small words</p></div>`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "urls.txt")
+	require.NoError(t, os.WriteFile(input, []byte(server.URL+"\n"), 0644))
+
+	t.Run("passing", func(t *testing.T) {
+		opts := options{input: input, noBank: true, output: filepath.Join(dir, "out", "results.json")}
+
+		var buf bytes.Buffer
+		assert.True(t, runSelfTest(context.Background(), &buf, selfTestChecks(opts, "")), buf.String())
+		assert.Contains(t, buf.String(), "PASS  test fetch: 3 words from "+server.URL+"\n")
+		assert.DirExists(t, filepath.Join(dir, "out"))
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		opts := options{input: filepath.Join(dir, "missing.txt"), output: filepath.Join(dir, "results.json")}
+
+		var buf bytes.Buffer
+		assert.False(t, runSelfTest(context.Background(), &buf, selfTestChecks(opts, filepath.Join(dir, "words.txt"))))
+		assert.Contains(t, buf.String(), "FAIL  input file: ")
+		assert.Contains(t, buf.String(), "FAIL  word bank: ")
+		assert.Contains(t, buf.String(), "FAIL  test fetch: no input URL to fetch\n")
+		assert.Contains(t, buf.String(), "1 of 4 checks passed\n")
+	})
+}
+
+func TestSelfTestChecksBundledInputs(t *testing.T) {
+	dir := t.TempDir()
+	saved := bundledInputs
+	bundledInputs = []string{filepath.Join(dir, "1k.txt"), filepath.Join(dir, "10k.txt")}
+	t.Cleanup(func() { bundledInputs = saved })
+	require.NoError(t, os.WriteFile(bundledInputs[0], []byte("http://a.com\nhttp://b.com\n"), 0644))
+	require.NoError(t, os.WriteFile(bundledInputs[1], []byte("http://c.com\n"), 0644))
+
+	bankPath := filepath.Join(dir, "words.txt")
+	require.NoError(t, os.WriteFile(bankPath, []byte("news\nstory\n"), 0644))
+
+	checks := selfTestChecks(options{}, bankPath)
+	detail, err := checks[0].run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2 URLs in "+bundledInputs[0]+", 1 URLs in "+bundledInputs[1], detail)
+
+	detail, err = checks[1].run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2 words in "+bankPath, detail)
+	assert.NoFileExists(t, filepath.Join(outputDir, "valid_word_bank.txt"))
+}
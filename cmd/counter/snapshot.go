@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+)
+
+// defaultSnapshotInterval is how often -snapshot is rewritten by default.
+const defaultSnapshotInterval = 5 * time.Minute
+
+// snapshot is the partial result written to -snapshot during a run.
+type snapshot struct {
+	SavedAt  time.Time             `json:"saved_at"`
+	TopWords []processor.WordCount `json:"top_words"`
+	Counts   map[string]int        `json:"counts"`
+}
+
+// saveSnapshot writes the counter's current top words and full counts to
+// path. It writes a temporary file first and renames it over path, so a
+// crash mid-write leaves the previous snapshot intact.
+func saveSnapshot(path string, counter *processor.SafeWordCounter, top int) error {
+	data, err := json.MarshalIndent(snapshot{
+		SavedAt:  time.Now(),
+		TopWords: counter.SnapshotTop(top),
+		Counts:   counter.Snapshot(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := fetcher.SaveToFile(tmp, string(data)); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeSnapshots saves a snapshot to path every interval until done closes.
+func writeSnapshots(path string, counter *processor.SafeWordCounter, top int, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := saveSnapshot(path, counter, top); err != nil {
+				logger.Error("Failed to save snapshot", "path", path, "err", err)
+			}
+		}
+	}
+}
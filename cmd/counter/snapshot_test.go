@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuaibbapputty/word-counter/internal/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	counter := processor.NewSafeWordCounter()
+	counter.Increment("hello", 3)
+	counter.Increment("world", 1)
+
+	require.NoError(t, saveSnapshot(path, counter, 1))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got snapshot
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, []processor.WordCount{{Word: "hello", Count: 3}}, got.TopWords)
+	assert.Equal(t, map[string]int{"hello": 3, "world": 1}, got.Counts)
+	assert.NoFileExists(t, path+".tmp")
+}
+
+func TestWriteSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	counter := processor.NewSafeWordCounter()
+	counter.Increment("hello", 1)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		writeSnapshots(path, counter, 5, 5*time.Millisecond, done)
+		close(finished)
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+	close(done)
+	<-finished
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+)
+
+// urlRecord is one line of the -ndjson log.
+type urlRecord struct {
+	URL     string  `json:"url"`
+	Words   int     `json:"words"`
+	Seconds float64 `json:"seconds,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+func writeURLRecord(w io.Writer, result fetcher.FetchResult) error {
+	return json.NewEncoder(w).Encode(urlRecord{
+		URL:     result.URL,
+		Words:   result.WordCount,
+		Seconds: result.Duration().Seconds(),
+		Error:   result.Error,
+	})
+}
+
+// urlLog buffers per-URL records on their way to a file.
+type urlLog struct {
+	file *os.File
+	*bufio.Writer
+}
+
+func createURLLog(path string) (*urlLog, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &urlLog{file: file, Writer: bufio.NewWriter(file)}, nil
+}
+
+func (l *urlLog) Close() error {
+	if err := l.Flush(); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuaibbapputty/word-counter/internal/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.ndjson")
+	urlRecords, err := createURLLog(path)
+	require.NoError(t, err)
+
+	results := []fetcher.FetchResult{
+		{URL: "http://example.com/a", Content: "three little words", WordCount: 3, StartTime: time.Unix(100, 0), FetchTime: time.Unix(101, 500e6)},
+		{URL: "http://example.com/b", Error: "unexpected status: 500"},
+	}
+	for _, result := range results {
+		require.NoError(t, writeURLRecord(urlRecords, result))
+	}
+	require.NoError(t, urlRecords.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"url":"http://example.com/a","words":3,"seconds":1.5}
+{"url":"http://example.com/b","words":0,"error":"unexpected status: 500"}
+`, string(content))
+}
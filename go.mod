@@ -4,6 +4,8 @@ go 1.22.8
 
 require (
 	github.com/PuerkitoBio/goquery v1.9.1
+	github.com/schollz/progressbar/v3 v3.17.0
+	github.com/stretchr/testify v1.9.0
 	golang.org/x/time v0.7.0
 )
 
@@ -13,8 +15,6 @@ require (
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	github.com/schollz/progressbar/v3 v3.17.0 // indirect
-	github.com/stretchr/testify v1.9.0 // indirect
 	golang.org/x/net v0.30.0 // indirect
 	golang.org/x/sys v0.26.0 // indirect
 	golang.org/x/term v0.25.0 // indirect
@@ -0,0 +1,70 @@
+package fetcher
+
+import (
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	rateEMAAlpha     = 0.1  // weight of the latest outcome in the rate-limit average
+	rateCleanEMA     = 0.05 // average below which the rate is raised
+	rateIncreaseStep = 0.1  // requests per second added per clean response
+	rateDecrease     = 0.5  // factor applied to the rate on each rate limit
+	minAdaptiveRate  = 0.1
+)
+
+// rateController adapts a limiter AIMD-style: the rate is cut
+// multiplicatively on every rate limit and raised additively while an
+// exponential moving average of rate-limited responses stays low.
+type rateController struct {
+	mu      sync.Mutex
+	limiter *rate.Limiter
+	min     float64
+	max     float64
+	ema     float64 // moving share of responses that were rate limited
+}
+
+// newRateController returns nil when max is not positive, leaving the
+// limiter's rate fixed.
+func newRateController(limiter *rate.Limiter, min, max float64) *rateController {
+	if max <= 0 {
+		return nil
+	}
+	if min <= 0 {
+		min = minAdaptiveRate
+	}
+	min = math.Min(min, max)
+	return &rateController{limiter: limiter, min: min, max: max}
+}
+
+func (c *rateController) success() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ema = (1 - rateEMAAlpha) * c.ema
+	if c.ema < rateCleanEMA {
+		c.setLimit(float64(c.limiter.Limit()) + rateIncreaseStep)
+	}
+}
+
+func (c *rateController) rateLimited() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ema = (1-rateEMAAlpha)*c.ema + rateEMAAlpha
+	c.setLimit(float64(c.limiter.Limit()) * rateDecrease)
+}
+
+func (c *rateController) setLimit(rps float64) {
+	c.limiter.SetLimit(rate.Limit(math.Max(c.min, math.Min(c.max, rps))))
+}
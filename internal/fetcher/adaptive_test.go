@@ -0,0 +1,46 @@
+package fetcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestRateController(t *testing.T) {
+	limiter := rate.NewLimiter(4, 1)
+	c := newRateController(limiter, 1, 5)
+
+	c.rateLimited()
+	assert.InDelta(t, 2, float64(limiter.Limit()), 1e-9)
+	c.rateLimited()
+	c.rateLimited()
+	assert.InDelta(t, 1, float64(limiter.Limit()), 1e-9, "never below the minimum")
+
+	// the rate stays put until the rate-limit average decays
+	c.success()
+	assert.InDelta(t, 1, float64(limiter.Limit()), 1e-9)
+
+	for i := 0; i < 200; i++ {
+		c.success()
+	}
+	assert.InDelta(t, 5, float64(limiter.Limit()), 1e-9, "never above the maximum")
+}
+
+func TestRateControllerDisabled(t *testing.T) {
+	limiter := rate.NewLimiter(4, 1)
+	c := newRateController(limiter, 1, 0)
+	assert.Nil(t, c)
+
+	c.rateLimited()
+	c.success()
+	assert.Equal(t, rate.Limit(4), limiter.Limit())
+}
+
+func TestNewFetcherAdaptiveRate(t *testing.T) {
+	f := NewFetcherWithConfig(FetcherConfig{RequestsPerSecond: 4, MaxRequestsPerSecond: 8})
+	assert.Equal(t, 4.0, f.GetMetrics().CurrentRate)
+
+	f.adaptive.rateLimited()
+	assert.Equal(t, 2.0, f.GetMetrics().CurrentRate)
+}
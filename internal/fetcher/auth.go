@@ -0,0 +1,44 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// AuthCredential authenticates requests to one host, with a bearer Token
+// when set and HTTP basic auth otherwise. Formatting one never reveals the
+// secrets, so a config can be logged safely.
+type AuthCredential struct {
+	Username string
+	Password string
+	Token    string
+}
+
+func (c AuthCredential) apply(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		return
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+}
+
+func (c AuthCredential) String() string {
+	if c.Token != "" {
+		return "bearer [redacted]"
+	}
+	return fmt.Sprintf("basic %s:[redacted]", c.Username)
+}
+
+func (c AuthCredential) GoString() string {
+	return "fetcher.AuthCredential{" + c.String() + "}"
+}
+
+// authFor returns the credential for req's host, matching host:port before
+// the bare host name.
+func (f *Fetcher) authFor(req *http.Request) (AuthCredential, bool) {
+	if cred, ok := f.config.Auth[req.URL.Host]; ok {
+		return cred, true
+	}
+	cred, ok := f.config.Auth[req.URL.Hostname()]
+	return cred, ok
+}
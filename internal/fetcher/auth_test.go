@@ -0,0 +1,69 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchURLsAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<div class="caas-body"><p>%s</p></div>`, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		auth map[string]AuthCredential
+		want string
+	}{
+		{
+			name: "basic by host and port",
+			auth: map[string]AuthCredential{u.Host: {Username: "user", Password: "pass"}},
+			want: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name: "bearer by host name",
+			auth: map[string]AuthCredential{u.Hostname(): {Token: "secret"}},
+			want: "Bearer secret",
+		},
+		{
+			name: "other host",
+			auth: map[string]AuthCredential{"example.com": {Token: "secret"}},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Auth = tt.auth
+			result := <-NewFetcherWithConfig(config).FetchURLs(context.Background(), []string{server.URL})
+
+			assert.Empty(t, result.Error)
+			assert.Equal(t, tt.want, result.Content)
+		})
+	}
+}
+
+func TestAuthCredentialRedacted(t *testing.T) {
+	creds := map[string]AuthCredential{
+		"a.com": {Username: "user", Password: "hunter2"},
+		"b.com": {Token: "tok-123"},
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		out := fmt.Sprintf(format, creds)
+		assert.NotContains(t, out, "hunter2", format)
+		assert.NotContains(t, out, "tok-123", format)
+	}
+}
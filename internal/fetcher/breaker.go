@@ -0,0 +1,105 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CircuitOpenError is returned for URLs whose host has failed too many times
+// in a row and is cooling down.
+type CircuitOpenError struct {
+	Host       string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for host %s, retry after %v", e.Host, e.RetryAfter)
+}
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	hosts     map[string]*hostCircuit
+}
+
+type hostCircuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hosts:     make(map[string]*hostCircuit),
+	}
+}
+
+// allow returns a CircuitOpenError while host is cooling down. Once the
+// cooldown passes a single further failure trips the breaker again.
+func (cb *circuitBreaker) allow(host string) error {
+	if cb.threshold <= 0 {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	hc, ok := cb.hosts[host]
+	if !ok || hc.openUntil.IsZero() {
+		return nil
+	}
+
+	if remaining := time.Until(hc.openUntil); remaining > 0 {
+		return &CircuitOpenError{Host: host, RetryAfter: remaining}
+	}
+
+	hc.openUntil = time.Time{}
+	hc.failures = cb.threshold - 1
+	return nil
+}
+
+func (cb *circuitBreaker) recordSuccess(host string) {
+	if cb.threshold <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	delete(cb.hosts, host)
+	cb.mu.Unlock()
+}
+
+// recordFailure counts a failure for host and reports whether it opened
+// the host's circuit.
+func (cb *circuitBreaker) recordFailure(host string) bool {
+	if cb.threshold <= 0 {
+		return false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	hc, ok := cb.hosts[host]
+	if !ok {
+		hc = &hostCircuit{}
+		cb.hosts[host] = hc
+	}
+
+	hc.failures++
+	if hc.failures >= cb.threshold && hc.openUntil.IsZero() {
+		hc.openUntil = time.Now().Add(cb.cooldown)
+		return true
+	}
+	return false
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
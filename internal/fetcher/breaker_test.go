@@ -0,0 +1,107 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	cb := newCircuitBreaker(2, 50*time.Millisecond)
+
+	cb.recordFailure("a.com")
+	assert.NoError(t, cb.allow("a.com"))
+
+	cb.recordFailure("a.com")
+	err := cb.allow("a.com")
+	var openErr *CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, "a.com", openErr.Host)
+	assert.NoError(t, cb.allow("b.com"))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, cb.allow("a.com"))
+
+	cb.recordFailure("a.com")
+	assert.Error(t, cb.allow("a.com"))
+}
+
+func TestCircuitBreakerSuccessResets(t *testing.T) {
+	cb := newCircuitBreaker(2, time.Minute)
+
+	cb.recordFailure("a.com")
+	cb.recordSuccess("a.com")
+	cb.recordFailure("a.com")
+	assert.NoError(t, cb.allow("a.com"))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := newCircuitBreaker(0, time.Minute)
+
+	for i := 0; i < 100; i++ {
+		cb.recordFailure("a.com")
+	}
+	assert.NoError(t, cb.allow("a.com"))
+}
+
+func TestFetchURLsCircuitBreaker(t *testing.T) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.RetryDelay = time.Millisecond
+	config.WorkerCount = 1
+	config.BreakerThreshold = 2
+	config.BreakerCooldown = time.Minute
+	f := NewFetcherWithConfig(config)
+
+	urls := []string{server.URL + "/1", server.URL + "/2", server.URL + "/3"}
+	var results []FetchResult
+	for result := range f.FetchURLs(context.Background(), urls) {
+		results = append(results, result)
+	}
+
+	assert.Equal(t, int64(2), hits.Load())
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.Contains(t, result.Error, "circuit open")
+	}
+	assert.Equal(t, int64(3), f.GetMetrics().Errors)
+}
+
+func TestFetchURLsBreakerOffByDefault(t *testing.T) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 1000
+	config.Burst = 20
+	config.MaxRetries = 1
+	f := NewFetcherWithConfig(config)
+
+	var urls []string
+	for i := 0; i < 20; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", server.URL, i))
+	}
+	for result := range f.FetchURLs(context.Background(), urls) {
+		assert.NotContains(t, result.Error, "circuit open")
+	}
+
+	assert.Equal(t, int64(20), hits.Load())
+}
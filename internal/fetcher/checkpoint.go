@@ -0,0 +1,113 @@
+package fetcher
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// Checkpoint appends completed URLs to a file, flushing it periodically so a
+// crashed crawl can be resumed with LoadCheckpoint and SkipCompleted.
+type Checkpoint struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func OpenCheckpoint(path string, flushInterval time.Duration) (*Checkpoint, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+
+	c := &Checkpoint{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		stop:   make(chan struct{}),
+	}
+
+	if flushInterval > 0 {
+		c.wg.Add(1)
+		go c.flushEvery(flushInterval)
+	}
+
+	return c, nil
+}
+
+func (c *Checkpoint) flushEvery(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			_ = c.Flush()
+		}
+	}
+}
+
+func (c *Checkpoint) Record(url string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.writer.WriteString(url + "\n"); err != nil {
+		return fmt.Errorf("record checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (c *Checkpoint) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.writer.Flush()
+}
+
+func (c *Checkpoint) Close() error {
+	close(c.stop)
+	c.wg.Wait()
+
+	if err := c.Flush(); err != nil {
+		c.file.Close()
+		return fmt.Errorf("flush checkpoint: %w", err)
+	}
+	return c.file.Close()
+}
+
+// LoadCheckpoint returns the URLs recorded in a checkpoint file. A missing
+// file means nothing has completed yet.
+func LoadCheckpoint(path string) (map[string]struct{}, error) {
+	urls, err := FetchFromFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]struct{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	completed := make(map[string]struct{}, len(urls))
+	for _, url := range urls {
+		completed[url] = struct{}{}
+	}
+	return completed, nil
+}
+
+func SkipCompleted(urls []string, completed map[string]struct{}) []string {
+	remaining := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if _, done := completed[url]; !done {
+			remaining = append(remaining, url)
+		}
+	}
+	return remaining
+}
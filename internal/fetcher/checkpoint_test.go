@@ -0,0 +1,52 @@
+package fetcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.txt")
+
+	completed, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Empty(t, completed)
+
+	c, err := OpenCheckpoint(path, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, c.Record("http://example.com/1"))
+	require.NoError(t, c.Record("http://example.com/2"))
+	require.NoError(t, c.Close())
+
+	c, err = OpenCheckpoint(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, c.Record("http://example.com/3"))
+	require.NoError(t, c.Close())
+
+	completed, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Len(t, completed, 3)
+
+	urls := []string{"http://example.com/1", "http://example.com/4", "http://example.com/3"}
+	assert.Equal(t, []string{"http://example.com/4"}, SkipCompleted(urls, completed))
+}
+
+func TestCheckpointPeriodicFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.txt")
+
+	c, err := OpenCheckpoint(path, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.Record("http://example.com/1"))
+
+	assert.Eventually(t, func() bool {
+		content, err := os.ReadFile(path)
+		return err == nil && string(content) == "http://example.com/1\n"
+	}, time.Second, 10*time.Millisecond)
+}
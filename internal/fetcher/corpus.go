@@ -0,0 +1,76 @@
+package fetcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const maxSlugLength = 100
+
+// corpusWriter saves each fetched document to its own file in dir. Files are
+// named after a slug of the URL, suffixed with the first free counter when
+// the name is already used.
+type corpusWriter struct {
+	dir    string
+	mutex  sync.Mutex
+	names  map[string]struct{} // file names handed out so far
+	suffix map[string]int      // last counter tried for each slug
+}
+
+func newCorpusWriter(dir string) *corpusWriter {
+	return &corpusWriter{dir: dir, names: make(map[string]struct{}), suffix: make(map[string]int)}
+}
+
+func (w *corpusWriter) save(url, content string) error {
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+	return SaveToFile(filepath.Join(w.dir, w.filename(url)), content)
+}
+
+func (w *corpusWriter) filename(url string) string {
+	slug := slugify(url)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	name := slug + ".txt"
+	for _, taken := w.names[name]; taken; _, taken = w.names[name] {
+		w.suffix[slug] = max(w.suffix[slug], 1) + 1
+		name = fmt.Sprintf("%s-%d.txt", slug, w.suffix[slug])
+	}
+	w.names[name] = struct{}{}
+	return name
+}
+
+// slugify turns a URL into a safe file name: its scheme is dropped and every
+// run of characters other than lowercase letters and digits becomes a dash.
+func slugify(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(url) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+
+	slug := strings.TrimRight(b.String(), "-")
+	if slug == "" {
+		return "document"
+	}
+	return slug
+}
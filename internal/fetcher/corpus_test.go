@@ -0,0 +1,83 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"simple", "https://example.com/news/story", "example-com-news-story"},
+		{"query and case", "http://Example.com/a?id=42&x=Y", "example-com-a-id-42-x-y"},
+		{"trailing slash", "https://example.com/", "example-com"},
+		{"path traversal", "../../etc/passwd", "etc-passwd"},
+		{"no usable characters", "https://???", "document"},
+		{"long", "https://example.com/" + strings.Repeat("a", 200), "example-com-" + strings.Repeat("a", maxSlugLength-len("example-com-"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slugify(tt.url))
+		})
+	}
+}
+
+func TestCorpusWriterCollisions(t *testing.T) {
+	w := newCorpusWriter(t.TempDir())
+
+	assert.Equal(t, "example-com-a.txt", w.filename("https://example.com/a"))
+	assert.Equal(t, "example-com-a-2.txt", w.filename("http://example.com/a"))
+	assert.Equal(t, "example-com-a-3.txt", w.filename("https://example.com/a/"))
+	assert.Equal(t, "example-com-b.txt", w.filename("https://example.com/b"))
+}
+
+func TestCorpusWriterSuffixCollisions(t *testing.T) {
+	w := newCorpusWriter(t.TempDir())
+
+	assert.Equal(t, "x-com-a.txt", w.filename("http://x.com/a"))
+	assert.Equal(t, "x-com-a-2.txt", w.filename("http://x.com/a"))
+	assert.Equal(t, "x-com-a-2-2.txt", w.filename("http://x.com/a/2"))
+	assert.Equal(t, "x-com-a-3.txt", w.filename("http://x.com/a/3"))
+	assert.Equal(t, "x-com-a-4.txt", w.filename("http://x.com/a"))
+}
+
+func TestFetchURLsOutputDir(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`<html><body><div class="caas-body"><p>Story text</p></div></body></html>`))
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "corpus")
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.OutputDir = dir
+	f := NewFetcherWithConfig(config)
+
+	for result := range f.FetchURLs(context.Background(), []string{server.URL + "/story", server.URL + "/missing"}) {
+		assert.Empty(t, result.Error)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, slugify(server.URL+"/story")+".txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "Story text", string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,127 @@
+package fetcher
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// crawlState tracks the URLs seen while FollowLinks is on, and how many
+// links deep each one was found, so every page is fetched once. Links wait
+// in pending until FetchURLs has a free worker for them.
+type crawlState struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	depth      map[string]int
+	maxDepth   int
+	pending    []string
+	active     int // URLs dispatched and not yet finished
+	discovered atomic.Int64
+}
+
+func newCrawlState(seeds []string, maxDepth int) *crawlState {
+	c := &crawlState{depth: make(map[string]int, len(seeds)), maxDepth: maxDepth}
+	c.cond = sync.NewCond(&c.mu)
+	for _, seed := range seeds {
+		c.depth[seed] = 0
+	}
+	return c
+}
+
+// follow queues the same-host links of a fetched page that haven't been
+// seen, unless the page is already at the maximum depth.
+func (c *crawlState) follow(pageURL string, result FetchResult) {
+	if c == nil || result.Parsed == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	depth := c.depth[pageURL]
+	if depth >= c.maxDepth {
+		return
+	}
+
+	base := result.FinalURL
+	if base == "" {
+		base = pageURL
+	}
+	for _, link := range sameHostLinks(base, result.Parsed.Links) {
+		if _, seen := c.depth[link]; !seen {
+			c.depth[link] = depth + 1
+			c.pending = append(c.pending, link)
+			c.discovered.Add(1)
+		}
+	}
+	c.cond.Broadcast()
+}
+
+func (c *crawlState) started() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.active++
+	c.mu.Unlock()
+}
+
+func (c *crawlState) finished() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.active--
+	c.cond.Broadcast()
+	c.mu.Unlock()
+}
+
+// next returns the next link to fetch, waiting while pages still being
+// fetched may add more. It reports false once the crawl is exhausted.
+func (c *crawlState) next() (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.pending) == 0 && c.active > 0 {
+		c.cond.Wait()
+	}
+	if len(c.pending) == 0 {
+		return "", false
+	}
+	link := c.pending[0]
+	c.pending = c.pending[1:]
+	return link, true
+}
+
+// total returns the URLs to fetch: the seeds plus the links found so far.
+func (c *crawlState) total(seeds int) int {
+	if c == nil {
+		return seeds
+	}
+	return seeds + int(c.discovered.Load())
+}
+
+// sameHostLinks resolves hrefs against base and keeps the http(s) links on
+// base's host, without fragments.
+func sameHostLinks(base string, hrefs []string) []string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	for _, href := range hrefs {
+		u, err := baseURL.Parse(strings.TrimSpace(href))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host != baseURL.Host {
+			continue
+		}
+		u.Fragment = ""
+		links = append(links, u.String())
+	}
+	return links
+}
@@ -0,0 +1,71 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameHostLinks(t *testing.T) {
+	links := sameHostLinks("http://example.com/dir/page", []string{
+		"other",
+		"/root#section",
+		"https://example.com/secure",
+		"http://elsewhere.com/",
+		"mailto:someone@example.com",
+		"#top",
+	})
+
+	assert.Equal(t, []string{
+		"http://example.com/dir/other",
+		"http://example.com/root",
+		"https://example.com/secure",
+		"http://example.com/dir/page",
+	}, links)
+}
+
+func TestFetchURLsFollowLinks(t *testing.T) {
+	pages := map[string]string{
+		"/":  `<a href="/a">a</a> <a href="/b#top">b</a> <a href="http://elsewhere.invalid/">x</a>`,
+		"/a": `<a href="/">home</a> <a href="/deep">deep</a>`,
+		"/b": `<a href="/a">a</a>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<div class="caas-body"><p>page %s</p></div>%s`, r.URL.Path, pages[r.URL.Path])
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     []string
+	}{
+		{"default depth", 0, []string{"/", "/a", "/b"}},
+		{"two levels", 2, []string{"/", "/a", "/b", "/deep"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFetcherWithConfig(FetcherConfig{
+				RequestsPerSecond: 1000,
+				WorkerCount:       2,
+				FollowLinks:       true,
+				MaxDepth:          tt.maxDepth,
+			})
+
+			var got []string
+			for result := range f.FetchURLs(context.Background(), []string{server.URL + "/"}) {
+				assert.Empty(t, result.Error)
+				got = append(got, result.URL[len(server.URL):])
+			}
+			sort.Strings(got)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package fetcher
+
+import "io"
+
+// ContentExtractor turns a fetched page into the text to count, so sites the
+// built-in selectors don't fit can use another strategy, e.g. readability.
+type ContentExtractor interface {
+	Extract(body io.Reader, url string) (string, error)
+}
+
+// ExtractorFunc adapts a function to the ContentExtractor interface.
+type ExtractorFunc func(body io.Reader, url string) (string, error)
+
+func (f ExtractorFunc) Extract(body io.Reader, url string) (string, error) {
+	return f(body, url)
+}
+
+// SelectorExtractor is the default extractor, reading the article title,
+// subheadline and body paragraphs by their CSS selectors.
+type SelectorExtractor struct{}
+
+func (SelectorExtractor) Extract(body io.Reader, _ string) (string, error) {
+	content, _, err := parseContent(body)
+	return content, err
+}
+
+// extract runs the configured extractor. A custom extractor's text becomes
+// the document body, leaving no title, subheadline or links.
+func (f *Fetcher) extract(body io.Reader, url string) (string, *ParsedDocument, error) {
+	if f.config.Extractor == nil {
+		return parseContent(body)
+	}
+
+	content, err := f.config.Extractor.Extract(body, url)
+	if err != nil {
+		return "", nil, err
+	}
+	content = normalizeSpace(content)
+	return content, &ParsedDocument{Body: content}, nil
+}
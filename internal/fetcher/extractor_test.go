@@ -0,0 +1,47 @@
+package fetcher
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorExtractor(t *testing.T) {
+	html := `<h1 id="caas-lead-header-undefined">Title</h1><div class="caas-body"><p>Body text</p></div>`
+
+	content, err := SelectorExtractor{}.Extract(strings.NewReader(html), "http://example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Title Body text", content)
+}
+
+func TestFetchURLsExtractor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<main>  plain   page text </main>`))
+	}))
+	defer server.Close()
+
+	var gotURL string
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.Extractor = ExtractorFunc(func(body io.Reader, url string) (string, error) {
+		gotURL = url
+		html, err := io.ReadAll(body)
+		text := strings.TrimSuffix(strings.TrimPrefix(string(html), "<main>"), "</main>")
+		return text, err
+	})
+
+	result := <-NewFetcherWithConfig(config).FetchURLs(context.Background(), []string{server.URL + "/page"})
+
+	assert.Empty(t, result.Error)
+	assert.Equal(t, server.URL+"/page", gotURL)
+	assert.Equal(t, "plain page text", result.Content)
+	assert.Equal(t, &ParsedDocument{Body: "plain page text"}, result.Parsed)
+	assert.Equal(t, 3, result.WordCount)
+}
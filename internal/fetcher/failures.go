@@ -0,0 +1,37 @@
+package fetcher
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FailedURL is a URL that produced no counted content, and why.
+type FailedURL struct {
+	URL        string
+	Error      string
+	RetryCount int
+}
+
+type failureLog struct {
+	mu       sync.Mutex
+	failures []FailedURL
+}
+
+func (l *failureLog) record(url, errorMsg string, retryCount int) {
+	l.mu.Lock()
+	l.failures = append(l.failures, FailedURL{URL: url, Error: errorMsg, RetryCount: retryCount})
+	l.mu.Unlock()
+}
+
+// Failures returns the URLs that failed or were skipped as thin so far, in
+// the order they finished.
+func (f *Fetcher) Failures() []FailedURL {
+	f.failures.mu.Lock()
+	defer f.failures.mu.Unlock()
+
+	return append([]FailedURL(nil), f.failures.failures...)
+}
+
+func thinPageMessage(words, min int) string {
+	return fmt.Sprintf("skipped: %d words, fewer than %d", words, min)
+}
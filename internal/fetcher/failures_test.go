@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcherFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/stub":
+			_, _ = w.Write([]byte(`<div class="caas-body"><p>page not found</p></div>`))
+		default:
+			_, _ = w.Write([]byte(`<div class="caas-body"><p>a full article with plenty of words</p></div>`))
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.WorkerCount = 1
+	config.MaxRetries = 2
+	config.RetryDelay = time.Millisecond
+	config.MinContentWords = 5
+	f := NewFetcherWithConfig(config)
+
+	for range f.FetchURLs(context.Background(), []string{server.URL + "/gone", server.URL + "/stub", server.URL + "/article"}) {
+	}
+
+	assert.Equal(t, []FailedURL{
+		{URL: server.URL + "/gone", Error: "unexpected status: 410", RetryCount: 1},
+		{URL: server.URL + "/stub", Error: "skipped: 3 words, fewer than 5"},
+	}, f.Failures())
+}
+
+func TestFetcherFailuresRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.MaxRetries = 2
+	config.BackoffDuration = 10 * time.Millisecond
+	f := NewFetcherWithConfig(config)
+
+	var results []FetchResult
+	for result := range f.FetchURLs(context.Background(), []string{server.URL}) {
+		results = append(results, result)
+	}
+
+	assert.Len(t, results, 1)
+	assert.Contains(t, results[0].Error, "Rate limit exceeded")
+	assert.Len(t, f.Failures(), 1)
+	metrics := f.GetMetrics()
+	assert.Equal(t, int64(1), metrics.Errors)
+	assert.Equal(t, int64(2), metrics.RateLimited)
+}
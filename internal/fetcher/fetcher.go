@@ -1,8 +1,14 @@
 package fetcher
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strings"
@@ -11,6 +17,7 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/shuaibbapputty/word-counter/internal/logging"
 	"golang.org/x/time/rate"
 )
 
@@ -22,36 +29,195 @@ const (
 	workers           = 10
 	resultBuffer      = 100
 	idleConnTimeout   = backoffSecs * 2
+	breakerCooldown   = backoffSecs * 2
+	burst             = 1
+	slowestURLs       = 10
+	maxIdleConns      = 100
 )
 
 type FetcherConfig struct {
 	RequestsPerSecond int
+	Burst             int // requests allowed at once after an idle spell, at least 1
 	BackoffDuration   time.Duration
 	MaxRetries        int
 	RetryDelay        time.Duration
 	WorkerCount       int
 	ResultBuffer      int
+
+	// MaxInFlight caps the HTTP requests in progress at once, separately
+	// from WorkerCount, which caps the URLs being worked on. Zero leaves
+	// requests bounded by WorkerCount alone.
+	MaxInFlight int
+
+	// Extractor reads the text to count from each page, SelectorExtractor's
+	// CSS selectors when nil.
+	Extractor ContentExtractor
+
+	// MaxIdleConns and MaxIdleConnsPerHost size the pool of kept-alive
+	// connections, 100 and WorkerCount when unset so every worker can reuse
+	// a connection to the same host. MaxConnsPerHost caps the connections
+	// to one host, e.g. to match its rate limit, and is unlimited when zero.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+
+	// Logger receives fetch, rate limit, circuit breaker, pause and retry
+	// events. Logging is off when nil.
+	Logger logging.Logger
+
+	// BreakerThreshold is the number of consecutive failures after which a
+	// host is skipped for BreakerCooldown. Zero, the default, disables the
+	// breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Quota caps the HTTP requests made in each QuotaWindow, 24 hours when
+	// unset, for APIs with a daily allowance. Once it is used up the
+	// remaining URLs fail with a QuotaExceededError. Zero disables it.
+	Quota       int
+	QuotaWindow time.Duration
+
+	// MaxTotalRetries caps the retries spent across all URLs, so a broad
+	// outage can't multiply into endless attempts. Zero means no cap.
+	MaxTotalRetries int
+
+	// IdleReadTimeout aborts a request once the server has sent nothing for
+	// this long, well before the client's overall timeout. Zero disables it.
+	IdleReadTimeout time.Duration
+
+	// PerURLTimeout bounds the time spent on one URL, including retries and
+	// backoff. Zero leaves URLs bounded only by the caller's context.
+	PerURLTimeout time.Duration
+
+	// MinContentWords drops pages with fewer words than this, counting them
+	// as skipped instead of sending them on. Zero keeps every page.
+	MinContentWords int
+
+	// TLSConfig configures HTTPS connections, e.g. to trust a private CA.
+	// InsecureSkipVerify turns off certificate checks entirely and is meant
+	// for tests against self-signed servers. Certificates are verified
+	// against the system roots by default.
+	TLSConfig          *tls.Config
+	InsecureSkipVerify bool
+
+	// Auth holds the credentials sent to each host, keyed by host name or
+	// host:port.
+	Auth map[string]AuthCredential
+
+	// FollowLinks also fetches the links found on each page that point to
+	// the page's own host, up to MaxDepth links away from the given URLs
+	// (one when unset). Each URL is fetched once.
+	FollowLinks bool
+	MaxDepth    int
+
+	// RespectRobots skips URLs disallowed by their host's robots.txt.
+	RespectRobots bool
+
+	// MaxRequestsPerSecond, when set, lets the rate adapt between
+	// MinRequestsPerSecond and this bound, starting from RequestsPerSecond:
+	// it is halved on each rate limit and raised while responses are clean.
+	MinRequestsPerSecond float64
+	MaxRequestsPerSecond float64
+
+	// Limiter, when set, throttles requests instead of a limiter built from
+	// RequestsPerSecond and Burst. Sharing one between fetchers gives them a
+	// common rate.
+	Limiter *rate.Limiter
+
+	// OutputDir, when set, receives a copy of each non-empty document's text
+	// as <slug of URL>.txt. A document that can't be saved is still sent on,
+	// with the failure in its Error.
+	OutputDir string
+
+	// SlowestURLs is how many of the slowest URLs SlowestURLs reports. Zero
+	// disables tracking.
+	SlowestURLs int
+
+	// OnProgress is called after each URL completes with the number done so
+	// far and the total. Calls are serialized, so it needn't be thread-safe.
+	OnProgress func(done, total int)
 }
 
 type Fetcher struct {
-	client  *http.Client
-	limiter *rate.Limiter
-	results chan FetchResult
-	metrics *fetcherMetrics
-	config  FetcherConfig
-	backoff *backoffManager
+	client     *http.Client
+	limiter    *rate.Limiter
+	results    chan FetchResult
+	metrics    *fetcherMetrics
+	config     FetcherConfig
+	backoff    *backoffManager
+	breaker    *circuitBreaker
+	quota      *requestQuota
+	robots     *robotsCache
+	adaptive   *rateController
+	slowest    *slowestTracker
+	corpus     *corpusWriter
+	crawl      *crawlState
+	inFlight   chan struct{} // nil when MaxInFlight is unset
+	failures   failureLog
+	logger     logging.Logger
+	progressMu sync.Mutex
 }
 
 type fetcherMetrics struct {
 	processed   atomic.Int64
 	errors      atomic.Int64
 	rateLimited atomic.Int64
+	skipped     atomic.Int64
+	retriesLeft atomic.Int64 // unspent MaxTotalRetries, negative once exhausted
+
+	requests     atomic.Int64 // HTTP requests that got a response
+	requestNanos atomic.Int64 // time spent on those requests, body included
+	bytesRead    atomic.Int64 // response body bytes read
+
+	stopReason atomic.Value // string, set once FetchURLs stops
+
+	goroutines     atomic.Int64 // fetch goroutines running now
+	peakGoroutines atomic.Int64 // most fetch goroutines running at once
+
+	statusMu    sync.Mutex
+	statusCodes map[int]int64 // responses per HTTP status code
+}
+
+// goroutineStarted counts a new fetch goroutine, raising the peak if needed.
+func (m *fetcherMetrics) goroutineStarted() {
+	n := m.goroutines.Add(1)
+	for peak := m.peakGoroutines.Load(); n > peak; peak = m.peakGoroutines.Load() {
+		if m.peakGoroutines.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+func (m *fetcherMetrics) recordStatus(code int) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	if m.statusCodes == nil {
+		m.statusCodes = make(map[int]int64)
+	}
+	m.statusCodes[code]++
+}
+
+func (m *fetcherMetrics) statusCounts() map[int]int64 {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	counts := make(map[int]int64, len(m.statusCodes))
+	for code, n := range m.statusCodes {
+		counts[code] = n
+	}
+	return counts
 }
 
+// backoffManager pauses every worker after a rate limit. signal is replaced
+// for each backoff and closed when it ends; both fields are guarded by mutex.
 type backoffManager struct {
-	isActive atomic.Bool
-	mutex    sync.Mutex
-	signal   chan struct{}
+	mutex  sync.Mutex
+	active bool
+	signal chan struct{}
+
+	paused  bool
+	resumed chan struct{} // closed by resume
 }
 type FetchResult struct {
 	URL        string
@@ -59,61 +225,206 @@ type FetchResult struct {
 	FetchTime  time.Time
 	Error      string
 	RetryCount int
+
+	// FinalURL is the URL actually fetched once redirects were followed.
+	FinalURL string
+	// Parsed splits Content into title, subheadline and body. It is nil for
+	// pages that weren't parsed, such as errors and 404s.
+	Parsed *ParsedDocument
+	// WordCount is the number of whitespace-separated words in Content,
+	// before any word bank filtering.
+	WordCount int
+	// StartTime is when the successful attempt's request began. It is zero
+	// for errors.
+	StartTime time.Time
 }
 
-func getDefaultConfig() FetcherConfig {
+// Duration is how long the successful attempt took, from StartTime to
+// FetchTime, or zero when the fetch failed.
+func (r FetchResult) Duration() time.Duration {
+	if r.StartTime.IsZero() {
+		return 0
+	}
+	return r.FetchTime.Sub(r.StartTime)
+}
+
+func DefaultConfig() FetcherConfig {
 	return FetcherConfig{
 		RequestsPerSecond: requestsPerSecond,
+		Burst:             burst,
 		BackoffDuration:   backoffSecs * time.Second,
 		MaxRetries:        maxRetries,
 		RetryDelay:        retryDelaySec * time.Second,
 		WorkerCount:       workers,
 		ResultBuffer:      resultBuffer,
+		BreakerCooldown:   breakerCooldown * time.Second,
+		SlowestURLs:       slowestURLs,
 	}
 }
 
 func NewFetcher() *Fetcher {
-	config := getDefaultConfig()
-
-	return &Fetcher{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				IdleConnTimeout: idleConnTimeout * time.Second,
-			},
+	return NewFetcherWithConfig(DefaultConfig())
+}
+
+// NewFetcherWithConfig creates a fetcher from config, using the defaults for
+// any numeric field left at zero.
+func NewFetcherWithConfig(config FetcherConfig) *Fetcher {
+	defaults := DefaultConfig()
+	if config.RequestsPerSecond <= 0 {
+		config.RequestsPerSecond = defaults.RequestsPerSecond
+	}
+	if config.Burst < 1 {
+		config.Burst = defaults.Burst
+	}
+	if config.BackoffDuration <= 0 {
+		config.BackoffDuration = defaults.BackoffDuration
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = defaults.MaxRetries
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	if config.WorkerCount <= 0 {
+		config.WorkerCount = defaults.WorkerCount
+	}
+	if config.ResultBuffer <= 0 {
+		config.ResultBuffer = defaults.ResultBuffer
+	}
+	if config.BreakerCooldown <= 0 {
+		config.BreakerCooldown = defaults.BreakerCooldown
+	}
+	if config.QuotaWindow <= 0 {
+		config.QuotaWindow = defaultQuotaWindow
+	}
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = maxIdleConns
+	}
+	if config.MaxIdleConnsPerHost <= 0 {
+		config.MaxIdleConnsPerHost = config.WorkerCount
+	}
+	if config.Logger == nil {
+		config.Logger = logging.Discard()
+	}
+
+	tlsConfig := config.TLSConfig
+	if config.InsecureSkipVerify {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		} else {
+			tlsConfig = tlsConfig.Clone()
+		}
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			IdleConnTimeout:     idleConnTimeout * time.Second,
+			TLSClientConfig:     tlsConfig,
+			MaxIdleConns:        config.MaxIdleConns,
+			MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     config.MaxConnsPerHost,
 		},
-		limiter: rate.NewLimiter(
+	}
+
+	var robots *robotsCache
+	if config.RespectRobots {
+		robots = newRobotsCache(client)
+	}
+
+	limiter := config.Limiter
+	if limiter == nil {
+		limiter = rate.NewLimiter(
 			rate.Every(time.Second/time.Duration(config.RequestsPerSecond)),
-			1,
-		),
+			config.Burst,
+		)
+	}
+
+	f := &Fetcher{
+		client:  client,
+		limiter: limiter,
 		results: make(chan FetchResult, config.ResultBuffer),
 		metrics: &fetcherMetrics{},
 		config:  config,
 		backoff: newBackoffManager(),
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+		quota:   newRequestQuota(config.Quota, config.QuotaWindow),
+		robots:  robots,
+		slowest: newSlowestTracker(config.SlowestURLs),
+		logger:  config.Logger,
+	}
+	f.adaptive = newRateController(limiter, config.MinRequestsPerSecond, config.MaxRequestsPerSecond)
+	if config.OutputDir != "" {
+		f.corpus = newCorpusWriter(config.OutputDir)
 	}
+	if config.MaxInFlight > 0 {
+		f.inFlight = make(chan struct{}, config.MaxInFlight)
+	}
+	f.metrics.retriesLeft.Store(int64(config.MaxTotalRetries))
+	return f
 }
 
 func (f *Fetcher) FetchURLs(ctx context.Context, urls []string) <-chan FetchResult {
 	urlPool := make(chan struct{}, f.config.WorkerCount)
 	var wg sync.WaitGroup
+	completed := 0
+
+	// dispatch waits for a free slot before starting a goroutine, so at most
+	// WorkerCount fetch goroutines exist however far behind the consumer is
+	dispatch := func(url string) {
+		urlPool <- struct{}{}
+		wg.Add(1)
+		f.crawl.started()
+		f.metrics.goroutineStarted()
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-urlPool }()
+			defer f.crawl.finished()
+			defer f.metrics.goroutines.Add(-1)
+
+			start := time.Now()
+			f.processURLWithTimeout(ctx, url)
+			f.slowest.record(url, time.Since(start))
+			f.reportProgress(&completed, f.crawl.total(len(urls)))
+		}()
+	}
+
+	if f.config.FollowLinks {
+		f.crawl = newCrawlState(urls, max(f.config.MaxDepth, 1))
+	}
+
+	// record why the run ended as soon as ctx does, even if the caller stops
+	// draining results before the workers exit
+	stopWatch := context.AfterFunc(ctx, func() {
+		f.metrics.stopReason.Store(stopReasonOf(ctx.Err()))
+	})
 
 	go func() {
 		defer close(f.results)
+		defer func() {
+			if stopWatch() {
+				f.metrics.stopReason.Store(StopCompleted)
+			} else {
+				// the AfterFunc may still be running, so don't leave the
+				// reason unset once results is closed
+				f.metrics.stopReason.Store(stopReasonOf(ctx.Err()))
+			}
+		}()
 
 		for _, url := range urls {
 			if ctx.Err() != nil {
-				return
+				break
 			}
-
-			urlPool <- struct{}{}
-			wg.Add(1)
-
-			go func(url string) {
-				defer wg.Done()
-				defer func() { <-urlPool }()
-
-				f.processURL(ctx, url)
-			}(url)
+			dispatch(url)
+		}
+		for ctx.Err() == nil {
+			link, ok := f.crawl.next()
+			if !ok {
+				break
+			}
+			dispatch(link)
 		}
 
 		wg.Wait()
@@ -122,23 +433,125 @@ func (f *Fetcher) FetchURLs(ctx context.Context, urls []string) <-chan FetchResu
 	return f.results
 }
 
+// Pause stops FetchURLs from starting HTTP requests until Resume, holding
+// its goroutines the way a rate limit backoff does. Requests already under
+// way finish, and PerURLTimeout keeps counting while paused.
+func (f *Fetcher) Pause() {
+	f.backoff.pause()
+	f.logger.Info("Fetching paused")
+}
+
+// Resume lets a paused fetcher start requests again.
+func (f *Fetcher) Resume() {
+	f.backoff.resume()
+	f.logger.Info("Fetching resumed")
+}
+
+// Paused reports whether the fetcher is paused.
+func (f *Fetcher) Paused() bool {
+	return f.backoff.isPaused()
+}
+
+// FetchSingle fetches one URL once, without retries, through the same rate
+// limiter, client and extraction as FetchURLs. It is meant for inspecting
+// how a page is parsed.
+func (f *Fetcher) FetchSingle(ctx context.Context, url string) FetchResult {
+	if err := f.limiter.Wait(ctx); err != nil {
+		return FetchResult{URL: url, Error: err.Error(), FetchTime: time.Now()}
+	}
+
+	start := time.Now()
+	result, err := f.fetch(ctx, url)
+	result.URL = url
+	result.FetchTime = time.Now()
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.StartTime = start
+	}
+	return result
+}
+
+func (f *Fetcher) reportProgress(done *int, total int) {
+	if f.config.OnProgress == nil {
+		return
+	}
+
+	f.progressMu.Lock()
+	defer f.progressMu.Unlock()
+
+	*done++
+	f.config.OnProgress(*done, total)
+}
+
+// Reasons FetchURLs stopped, as reported by GetMetrics.
+const (
+	StopCompleted = "completed"
+	StopTimeout   = "timeout"
+	StopCancelled = "cancelled"
+)
+
+func stopReasonOf(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return StopTimeout
+	}
+	return StopCancelled
+}
+
+// ErrURLTimeout is reported for URLs that used up their PerURLTimeout.
+var ErrURLTimeout = errors.New("per-URL timeout exceeded")
+
+func (f *Fetcher) processURLWithTimeout(ctx context.Context, url string) {
+	if f.config.PerURLTimeout <= 0 {
+		f.processURL(ctx, url)
+		return
+	}
+
+	urlCtx, cancel := context.WithTimeoutCause(ctx, f.config.PerURLTimeout, ErrURLTimeout)
+	defer cancel()
+
+	f.processURL(urlCtx, url)
+}
+
 func (f *Fetcher) processURL(ctx context.Context, url string) {
+	if f.robots != nil && !f.robots.allowed(url) {
+		f.sendResult(url, "", 0, robotsBlockedMessage)
+		return
+	}
+
+	host := hostOf(url)
 	for attempt := 0; attempt < f.config.MaxRetries; attempt++ {
 		if ctx.Err() != nil {
+			f.abandon(ctx, url, attempt)
 			return
 		}
 
-		if f.backoff.isActive.Load() {
-			select {
-			case <-ctx.Done():
-				return
-			case <-f.backoff.signal:
-			}
+		if err := f.breaker.allow(host); err != nil {
+			f.metrics.errors.Add(1)
+			f.sendResult(url, "", attempt, err.Error())
+			return
+		}
+
+		if err := f.awaitBackoff(ctx); err != nil {
+			f.abandon(ctx, url, attempt)
+			return
+		}
+
+		if err := f.quota.check(); err != nil {
+			f.metrics.errors.Add(1)
+			f.sendResult(url, "", attempt, err.Error())
+			return
 		}
 
 		if err := f.limiter.Wait(ctx); err != nil {
+			if _, ok := ctx.Deadline(); ok {
+				// Wait fails early when the next slot is past the deadline;
+				// wait for it so abandon sees the deadline's cause.
+				<-ctx.Done()
+			}
 			select {
 			case <-ctx.Done():
+				f.abandon(ctx, url, attempt)
 				return
 			default:
 				f.sendResult(url, "", attempt, err.Error())
@@ -146,110 +559,267 @@ func (f *Fetcher) processURL(ctx context.Context, url string) {
 			return
 		}
 
-		content, err := f.fetch(ctx, url)
+		if err := f.quota.take(); err != nil {
+			f.metrics.errors.Add(1)
+			f.sendResult(url, "", attempt, err.Error())
+			return
+		}
+
+		start := time.Now()
+		result, err := f.fetch(ctx, url)
 		if err == nil {
+			f.breaker.recordSuccess(host)
+			f.adaptive.success()
 			f.metrics.processed.Add(1)
+			f.crawl.follow(url, result)
+			if f.isThin(result.WordCount) {
+				f.metrics.skipped.Add(1)
+				f.failures.record(url, thinPageMessage(result.WordCount, f.config.MinContentWords), attempt)
+				return
+			}
 			select {
 			case <-ctx.Done():
+				f.abandon(ctx, url, attempt)
 				return
 			default:
-				f.sendResult(url, content, attempt, "")
+				result.StartTime = start
+				result.FetchTime = time.Now()
+				result.RetryCount = attempt
+				if f.corpus != nil && result.Content != "" {
+					if err := f.corpus.save(url, result.Content); err != nil {
+						result.Error = fmt.Sprintf("save document: %v", err)
+					}
+				}
+				f.send(result)
 			}
 			return
 		}
 
 		if isRateLimit(err) {
 			f.metrics.rateLimited.Add(1)
-			f.handleRateLimit()
+			f.adaptive.rateLimited()
+			f.handleRateLimit(url)
+			if attempt == f.config.MaxRetries-1 || !f.takeRetry() {
+				f.metrics.errors.Add(1)
+				f.sendResult(url, "", attempt, err.Error())
+				return
+			}
 			continue
 		}
 
-		if attempt == f.config.MaxRetries-1 {
-			f.metrics.errors.Add(1)
+		if f.breaker.recordFailure(host) {
+			f.logger.Warn("Too many failures, skipping host", "host", host, "cooldown", f.config.BreakerCooldown)
+		}
+
+		if attempt == f.config.MaxRetries-1 || !f.takeRetry() {
 			select {
 			case <-ctx.Done():
+				f.abandon(ctx, url, attempt)
 				return
 			default:
+				f.metrics.errors.Add(1)
 				f.sendResult(url, "", attempt, err.Error())
 			}
 			return
 		}
 
+		f.logger.Debug("Retrying", "url", url, "attempt", attempt+1, "err", err)
 		select {
 		case <-ctx.Done():
+			f.abandon(ctx, url, attempt)
 			return
 		case <-time.After(f.calculateBackoff(attempt)):
 		}
 	}
 }
 
-func (f *Fetcher) fetch(ctx context.Context, url string) (string, error) {
+// abandon records a timeout for url when ctx ended because its PerURLTimeout
+// passed. Cancellation of the whole run stays silent as before.
+func (f *Fetcher) abandon(ctx context.Context, url string, attempt int) {
+	if errors.Is(context.Cause(ctx), ErrURLTimeout) {
+		f.metrics.errors.Add(1)
+		f.sendResult(url, "", attempt, ErrURLTimeout.Error())
+	}
+}
+
+// takeRetry reports whether the shared retry budget allows another attempt,
+// spending one retry if so.
+func (f *Fetcher) takeRetry() bool {
+	if f.config.MaxTotalRetries <= 0 {
+		return true
+	}
+	return f.metrics.retriesLeft.Add(-1) >= 0
+}
+
+func (f *Fetcher) isThin(wordCount int) bool {
+	return f.config.MinContentWords > 0 && wordCount < f.config.MinContentWords
+}
+
+// awaitBackoff blocks while requests are paused or backing off from a rate
+// limit, returning ctx's error if it ends first.
+func (f *Fetcher) awaitBackoff(ctx context.Context) error {
+	for signal := f.backoff.wait(); signal != nil; signal = f.backoff.wait() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-signal:
+		}
+	}
+	return nil
+}
+
+// fetch returns the page content and the URL it was served from after any
+// redirects, leaving the timing and retry fields of the result to the caller.
+func (f *Fetcher) fetch(ctx context.Context, url string) (FetchResult, error) {
+	if f.inFlight != nil {
+		select {
+		case f.inFlight <- struct{}{}:
+			defer func() { <-f.inFlight }()
+		case <-ctx.Done():
+			return FetchResult{}, ctx.Err()
+		}
+	}
+
+	// check again now the rate limiter and in-flight slots are held, so
+	// requests queued behind them don't go out during a pause or backoff
+	if err := f.awaitBackoff(ctx); err != nil {
+		return FetchResult{}, err
+	}
+
+	var idle *idleTimer
+	if f.config.IdleReadTimeout > 0 {
+		ctx, idle = withIdleTimeout(ctx, f.config.IdleReadTimeout)
+		defer idle.stop()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return FetchResult{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if cred, ok := f.authFor(req); ok {
+		cred.apply(req)
 	}
 
+	start := time.Now()
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("execute request: %w", err)
+		f.logger.Debug("Request failed", "url", url, "duration", time.Since(start), "err", err)
+		return FetchResult{}, fmt.Errorf("execute request: %w", idle.cause(err))
 	}
 	defer resp.Body.Close()
+	if idle != nil {
+		resp.Body = idle.wrap(resp.Body)
+	}
+	counted := &countingReader{r: resp.Body}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{counted, resp.Body}
 
-	return f.handleResponse(resp)
-}
+	result := FetchResult{URL: url, FinalURL: resp.Request.URL.String()}
+	result.Content, result.Parsed, err = f.handleResponse(resp)
+	result.WordCount = len(strings.Fields(result.Content))
 
-func (f *Fetcher) handleRateLimit() {
-	if !f.backoff.isActive.Load() {
-		f.backoff.mutex.Lock()
-		f.backoff.isActive.Store(true)
-		f.backoff.signal = make(chan struct{}, 1)
-		f.backoff.mutex.Unlock()
+	elapsed := time.Since(start)
+	f.logger.Debug("Fetched", "url", url, "status", resp.StatusCode, "duration", elapsed, "bytes", counted.n)
 
-		go func() {
-			time.Sleep(f.config.BackoffDuration)
-			f.backoff.isActive.Store(false)
-			close(f.backoff.signal)
-		}()
+	f.metrics.requests.Add(1)
+	f.metrics.requestNanos.Add(int64(elapsed))
+	f.metrics.bytesRead.Add(counted.n)
+	return result, idle.cause(err)
+}
+
+func (f *Fetcher) handleRateLimit(url string) {
+	if f.backoff.start(f.config.BackoffDuration) {
+		f.logger.Warn("Rate limited, pausing requests", "url", url, "backoff", f.config.BackoffDuration)
 	}
 }
 
-func (f *Fetcher) handleResponse(resp *http.Response) (string, error) {
+func (f *Fetcher) handleResponse(resp *http.Response) (string, *ParsedDocument, error) {
+	f.metrics.recordStatus(resp.StatusCode)
+
 	switch resp.StatusCode {
 	case http.StatusOK:
-		return f.parseContent(resp)
+		return f.extract(resp.Body, resp.Request.URL.String())
 	case http.StatusTooManyRequests, 999:
-		return "", &RateLimitError{
+		return "", nil, &RateLimitError{
 			RetryAfter: f.config.BackoffDuration,
 			Message:    fmt.Sprintf("Rate limit exceeded (Status %d)", resp.StatusCode),
 		}
 	case http.StatusNotFound:
-		return "", nil
+		return "", nil, nil
 	default:
-		return "", fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		return "", nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
 	}
 }
 
-func (f *Fetcher) parseContent(resp *http.Response) (string, error) {
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+// ParsedDocument is an article's text split by where it appeared, so title
+// words can be treated differently from body words.
+type ParsedDocument struct {
+	Title   string
+	Subhead string
+	Body    string
+
+	// Links are the href attributes of the page's links, unresolved.
+	Links []string
+}
+
+const (
+	titleSelector   = "#caas-lead-header-undefined"
+	subheadSelector = ".caas-subheadline"
+	bodySelector    = ".caas-body p"
+)
+
+// ParseDocument extracts the article title, subheadline and body from HTML.
+func ParseDocument(r io.Reader) (*ParsedDocument, error) {
+	_, parsed, err := parseContent(r)
+	return parsed, err
+}
+
+// parseContent returns the article text in document order along with the
+// same text split into its parts.
+func parseContent(r io.Reader) (string, *ParsedDocument, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
 	if err != nil {
-		return "", fmt.Errorf("parse HTML: %w", err)
+		return "", nil, fmt.Errorf("parse HTML: %w", err)
 	}
 
 	doc.Find(".caas-figure, .caas-img, .t-meta, .caas-carousel, .caas-iframe-wrapper, .twitter-tweet-wrapper").Remove()
 
-	contentBuilder := strings.Builder{}
-	selectors := []string{
-		"#caas-lead-header-undefined",
-		".caas-subheadline",
-		".caas-body p",
-	}
+	var contentBuilder, title, subhead, body strings.Builder
+	selectors := []string{titleSelector, subheadSelector, bodySelector}
 
 	doc.Find(strings.Join(selectors, ", ")).Each(func(_ int, s *goquery.Selection) {
-		contentBuilder.WriteString(s.Text())
+		text := s.Text()
+		contentBuilder.WriteString(text)
 		contentBuilder.WriteByte(' ')
+
+		part := &body
+		switch {
+		case s.Is(titleSelector):
+			part = &title
+		case s.Is(subheadSelector):
+			part = &subhead
+		}
+		part.WriteString(text)
+		part.WriteByte(' ')
+	})
+
+	parsed := &ParsedDocument{
+		Title:   normalizeSpace(title.String()),
+		Subhead: normalizeSpace(subhead.String()),
+		Body:    normalizeSpace(body.String()),
+	}
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		parsed.Links = append(parsed.Links, href)
 	})
+	return normalizeSpace(contentBuilder.String()), parsed, nil
+}
 
-	return strings.Join(strings.Fields(contentBuilder.String()), " "), nil
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
 }
 
 func (f *Fetcher) calculateBackoff(attempt int) time.Duration {
@@ -257,14 +827,20 @@ func (f *Fetcher) calculateBackoff(attempt int) time.Duration {
 }
 
 func (f *Fetcher) sendResult(url, content string, retryCount int, errorMsg string) {
-	result := FetchResult{
+	if errorMsg != "" {
+		f.failures.record(url, errorMsg, retryCount)
+		f.logger.Warn("Fetch failed", "url", url, "attempts", retryCount+1, "err", errorMsg)
+	}
+	f.send(FetchResult{
 		URL:        url,
 		Content:    content,
 		Error:      errorMsg,
 		FetchTime:  time.Now(),
 		RetryCount: retryCount,
-	}
+	})
+}
 
+func (f *Fetcher) send(result FetchResult) {
 	select {
 	case f.results <- result:
 	default:
@@ -272,21 +848,151 @@ func (f *Fetcher) sendResult(url, content string, retryCount int, errorMsg strin
 	}
 }
 
+// MaxInputBytes is the largest URL list FetchFromFile and the CSV and JSON
+// readers will read, measured after decompression.
+const MaxInputBytes = 256 << 20
+
+// InputTooLargeError reports a URL list over the byte limit.
+type InputTooLargeError struct {
+	Path  string
+	Size  int64 // decompressed bytes read before giving up, just over Limit
+	Limit int64
+	Lines int // lines read before the limit was reached
+}
+
+func (e *InputTooLargeError) Error() string {
+	return fmt.Sprintf("input %s exceeds the %d byte limit: stopped after %d bytes and %d lines", e.Path, e.Limit, e.Size, e.Lines)
+}
+
+// FetchFromFile reads one URL per line. Like the other input readers it
+// transparently decompresses files with a .gz extension or a gzip header.
 func FetchFromFile(filePath string) ([]string, error) {
-	content, err := os.ReadFile(filePath)
+	return FetchFromFileContext(context.Background(), filePath, MaxInputBytes)
+}
+
+// FetchFromFileContext is FetchFromFile with cancellation and a byte limit.
+// Files over maxBytes fail with an *InputTooLargeError; zero or less means
+// no limit.
+func FetchFromFileContext(ctx context.Context, filePath string, maxBytes int64) ([]string, error) {
+	in, err := openInput(filePath, maxBytes)
 	if err != nil {
-		return nil, fmt.Errorf("read file: %w", err)
+		return nil, err
 	}
+	defer in.Close()
 
 	var urls []string
-	for _, line := range strings.Split(string(content), "\n") {
-		if line = strings.TrimSpace(line); line != "" {
+	lines := 0
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, in.maxLine())
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		lines++
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
 			urls = append(urls, line)
 		}
 	}
+	if err := in.tooLarge(lines); err != nil {
+		return nil, err
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", filePath, err)
+	}
 	return urls, nil
 }
 
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// inputReader reads an input file, decompressing it when gzipped, and stops
+// one byte past maxBytes of decompressed content so tooLarge can tell.
+type inputReader struct {
+	io.Reader
+	path     string
+	file     *os.File
+	zr       *gzip.Reader
+	counted  *countingReader
+	maxBytes int64
+}
+
+func openInput(filePath string, maxBytes int64) (*inputReader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	in := &inputReader{path: filePath, file: file, maxBytes: maxBytes}
+	buffered := bufio.NewReader(file)
+	var r io.Reader = buffered
+	if magic, _ := buffered.Peek(len(gzipMagic)); strings.HasSuffix(filePath, ".gz") || bytes.Equal(magic, gzipMagic) {
+		if in.zr, err = gzip.NewReader(buffered); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("decompress %s: %w", filePath, err)
+		}
+		r = in.zr
+	}
+
+	in.counted = &countingReader{r: r}
+	in.Reader = in.counted
+	if maxBytes > 0 {
+		in.Reader = io.LimitReader(in.counted, maxBytes+1)
+	}
+	return in, nil
+}
+
+// maxLine is the longest line a bufio.Scanner over in may need to hold.
+func (in *inputReader) maxLine() int {
+	if in.maxBytes > 0 {
+		return int(in.maxBytes) + 1
+	}
+	return MaxInputBytes
+}
+
+// tooLarge returns an *InputTooLargeError once more than maxBytes were read.
+func (in *inputReader) tooLarge(lines int) error {
+	if in.maxBytes > 0 && in.counted.n > in.maxBytes {
+		return &InputTooLargeError{Path: in.path, Size: in.counted.n, Limit: in.maxBytes, Lines: lines}
+	}
+	return nil
+}
+
+func (in *inputReader) Close() error {
+	if in.zr != nil {
+		in.zr.Close()
+	}
+	return in.file.Close()
+}
+
+// readInputFile reads a whole CSV or JSON input, failing with an
+// *InputTooLargeError over maxBytes once decompressed.
+func readInputFile(filePath string, maxBytes int64) ([]byte, error) {
+	in, err := openInput(filePath, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	content, err := io.ReadAll(in)
+	if err := in.tooLarge(bytes.Count(content, []byte("\n"))); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filePath, err)
+	}
+	return content, nil
+}
+
 func SaveToFile(filePath string, content string) error {
 	return os.WriteFile(filePath, []byte(content), 0644)
 }
@@ -309,20 +1015,130 @@ func (f *Fetcher) GetMetrics() struct {
 	Processed   int64
 	Errors      int64
 	RateLimited int64
+	Skipped     int64
+	// RetriesRemaining is the unspent MaxTotalRetries budget, or -1 when
+	// retries aren't capped.
+	RetriesRemaining int64
+	// StatusCodes counts responses by HTTP status code.
+	StatusCodes map[int]int64
+	// QuotaRemaining is the requests left in the current quota window, or
+	// -1 without a Quota.
+	QuotaRemaining int64
+	// CurrentRate is the limiter's requests per second, which changes over
+	// the run when MaxRequestsPerSecond is set.
+	CurrentRate float64
+	// StopReason is StopCompleted, StopTimeout or StopCancelled once
+	// FetchURLs has stopped, and empty while it runs.
+	StopReason string
+	// Goroutines is the fetch goroutines running now, and PeakGoroutines
+	// the most that ran at once, never more than WorkerCount.
+	Goroutines     int64
+	PeakGoroutines int64
 } {
+	stopReason, _ := f.metrics.stopReason.Load().(string)
+	retriesRemaining := int64(-1)
+	if f.config.MaxTotalRetries > 0 {
+		retriesRemaining = max(f.metrics.retriesLeft.Load(), 0)
+	}
+
 	return struct {
-		Processed   int64
-		Errors      int64
-		RateLimited int64
+		Processed        int64
+		Errors           int64
+		RateLimited      int64
+		Skipped          int64
+		RetriesRemaining int64
+		StatusCodes      map[int]int64
+		QuotaRemaining   int64
+		CurrentRate      float64
+		StopReason       string
+		Goroutines       int64
+		PeakGoroutines   int64
 	}{
-		Processed:   f.metrics.processed.Load(),
-		Errors:      f.metrics.errors.Load(),
-		RateLimited: f.metrics.rateLimited.Load(),
+		Processed:        f.metrics.processed.Load(),
+		Errors:           f.metrics.errors.Load(),
+		RateLimited:      f.metrics.rateLimited.Load(),
+		Skipped:          f.metrics.skipped.Load(),
+		RetriesRemaining: retriesRemaining,
+		StatusCodes:      f.metrics.statusCounts(),
+		QuotaRemaining:   f.quota.remaining(),
+		CurrentRate:      float64(f.limiter.Limit()),
+		StopReason:       stopReason,
+		Goroutines:       f.metrics.goroutines.Load(),
+		PeakGoroutines:   f.metrics.peakGoroutines.Load(),
 	}
 }
 
+// SlowestURLs returns the slowest URLs fetched so far, slowest first, up to
+// the configured SlowestURLs.
+func (f *Fetcher) SlowestURLs() []URLLatency {
+	return f.slowest.slowest()
+}
+
 func newBackoffManager() *backoffManager {
-	return &backoffManager{
-		signal: make(chan struct{}, 1),
+	return &backoffManager{}
+}
+
+// wait returns a channel that is closed when the fetcher is resumed or the
+// current backoff ends, or nil when neither a pause nor a backoff is active.
+func (b *backoffManager) wait() <-chan struct{} {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch {
+	case b.paused:
+		return b.resumed
+	case b.active:
+		return b.signal
+	default:
+		return nil
 	}
 }
+
+func (b *backoffManager) pause() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.paused {
+		b.paused = true
+		b.resumed = make(chan struct{})
+	}
+}
+
+func (b *backoffManager) resume() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.paused {
+		b.paused = false
+		close(b.resumed)
+	}
+}
+
+func (b *backoffManager) isPaused() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.paused
+}
+
+// start begins a backoff of d unless one is already running, so overlapping
+// rate limits share a single signal that is closed exactly once. It reports
+// whether a new backoff began.
+func (b *backoffManager) start(d time.Duration) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.active {
+		return false
+	}
+	b.active = true
+	signal := make(chan struct{})
+	b.signal = signal
+
+	time.AfterFunc(d, func() {
+		b.mutex.Lock()
+		b.active = false
+		b.mutex.Unlock()
+		close(signal)
+	})
+	return true
+}
@@ -1,15 +1,23 @@
 package fetcher
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 func TestNewFetcher(t *testing.T) {
@@ -44,6 +52,378 @@ func TestFetchURLs(t *testing.T) {
 
 	assert.Empty(t, result.Error)
 	assert.Contains(t, result.Content, "Header Test content")
+	assert.Equal(t, 3, result.WordCount)
+	require.NotNil(t, result.Parsed)
+	assert.Equal(t, "Header", result.Parsed.Title)
+	assert.Equal(t, "Test content", result.Parsed.Body)
+}
+
+func TestFetchSingle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>single page</p></div>`))
+	}))
+	defer server.Close()
+
+	f := NewFetcher()
+	result := f.FetchSingle(context.Background(), server.URL+"/page")
+	assert.Empty(t, result.Error)
+	assert.Equal(t, server.URL+"/page", result.URL)
+	assert.Equal(t, "single page", result.Content)
+	assert.Equal(t, 2, result.WordCount)
+
+	result = f.FetchSingle(context.Background(), server.URL+"/broken")
+	assert.Equal(t, server.URL+"/broken", result.URL)
+	assert.Contains(t, result.Error, "unexpected status: 500")
+}
+
+func TestFetchURLsStopReason(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>page</p></div>`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		ctx  func() (context.Context, context.CancelFunc)
+		url  string
+		want string
+	}{
+		{
+			name: "completed",
+			ctx:  func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			url:  server.URL,
+			want: StopCompleted,
+		},
+		{
+			name: "timeout",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			url:  server.URL + "/slow",
+			want: StopTimeout,
+		},
+		{
+			name: "cancelled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(50*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			url:  server.URL + "/slow",
+			want: StopCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			f := NewFetcher()
+			assert.Empty(t, f.GetMetrics().StopReason)
+			for range f.FetchURLs(ctx, []string{tt.url}) {
+			}
+			assert.Equal(t, tt.want, f.GetMetrics().StopReason)
+		})
+	}
+}
+
+func TestFetchURLsFinalURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/canonical", http.StatusMovedPermanently)
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>moved</p></div>`))
+	}))
+	defer server.Close()
+
+	result := <-NewFetcher().FetchURLs(context.Background(), []string{server.URL + "/old"})
+
+	assert.Empty(t, result.Error)
+	assert.Equal(t, server.URL+"/old", result.URL)
+	assert.Equal(t, server.URL+"/canonical", result.FinalURL)
+	assert.Equal(t, "moved", result.Content)
+	assert.False(t, result.StartTime.IsZero())
+	assert.False(t, result.FetchTime.Before(result.StartTime))
+	assert.Equal(t, result.FetchTime.Sub(result.StartTime), result.Duration())
+}
+
+func TestFetchURLsMinContentWords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stub" {
+			_, _ = w.Write([]byte(`<div class="caas-body"><p>page not found</p></div>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>a full article with plenty of words</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.MinContentWords = 5
+	f := NewFetcherWithConfig(config)
+
+	var results []FetchResult
+	for result := range f.FetchURLs(context.Background(), []string{server.URL + "/stub", server.URL + "/article"}) {
+		results = append(results, result)
+	}
+
+	require.Len(t, results, 1)
+	assert.Equal(t, server.URL+"/article", results[0].URL)
+	assert.Equal(t, int64(2), f.GetMetrics().Processed)
+	assert.Equal(t, int64(1), f.GetMetrics().Skipped)
+}
+
+func TestFetchURLsMaxInFlight(t *testing.T) {
+	var inFlight, peak atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>content</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 1000
+	config.Burst = 10
+	config.WorkerCount = 8
+	config.MaxInFlight = 2
+	f := NewFetcherWithConfig(config)
+
+	urls := make([]string, 8)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/%d", server.URL, i)
+	}
+	count := 0
+	for range f.FetchURLs(context.Background(), urls) {
+		count++
+	}
+
+	assert.Equal(t, len(urls), count)
+	assert.Equal(t, int64(2), peak.Load())
+}
+
+func TestFetchURLsGoroutineGauge(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>content</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 1000
+	config.Burst = 10
+	config.WorkerCount = 3
+	f := NewFetcherWithConfig(config)
+
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/%d", server.URL, i)
+	}
+	results := f.FetchURLs(context.Background(), urls)
+
+	require.Eventually(t, func() bool { return f.GetMetrics().Goroutines == 3 }, time.Second, time.Millisecond)
+	close(release)
+	for range results {
+	}
+
+	metrics := f.GetMetrics()
+	assert.Equal(t, int64(0), metrics.Goroutines)
+	assert.Equal(t, int64(3), metrics.PeakGoroutines)
+}
+
+func TestFetchURLsPerURLTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>quick</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.PerURLTimeout = 50 * time.Millisecond
+	f := NewFetcherWithConfig(config)
+
+	results := make(map[string]FetchResult)
+	for result := range f.FetchURLs(context.Background(), []string{server.URL + "/slow", server.URL + "/fast"}) {
+		results[result.URL] = result
+	}
+
+	require.Len(t, results, 2)
+	assert.Equal(t, ErrURLTimeout.Error(), results[server.URL+"/slow"].Error)
+	assert.Equal(t, "quick", results[server.URL+"/fast"].Content)
+	assert.Equal(t, int64(1), f.GetMetrics().Errors)
+}
+
+func TestFetchURLsPerURLTimeoutOnLastAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.MaxRetries = 1
+	config.PerURLTimeout = 50 * time.Millisecond
+	f := NewFetcherWithConfig(config)
+
+	result := <-f.FetchURLs(context.Background(), []string{server.URL})
+
+	assert.Equal(t, ErrURLTimeout.Error(), result.Error)
+	assert.Equal(t, int64(1), f.GetMetrics().Errors)
+}
+
+func TestFetchURLsPerURLTimeoutWaitingForLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>quick</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 1
+	config.WorkerCount = 3
+	config.PerURLTimeout = 200 * time.Millisecond
+	f := NewFetcherWithConfig(config)
+
+	var timedOut int
+	for result := range f.FetchURLs(context.Background(), []string{server.URL + "/1", server.URL + "/2", server.URL + "/3"}) {
+		if result.Error != "" {
+			assert.Equal(t, ErrURLTimeout.Error(), result.Error)
+			timedOut++
+		}
+	}
+
+	assert.Equal(t, 2, timedOut)
+	assert.Equal(t, int64(2), f.GetMetrics().Errors)
+}
+
+func TestFetchURLsRetryBudget(t *testing.T) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.RetryDelay = time.Millisecond
+	config.WorkerCount = 1
+	config.MaxTotalRetries = 2
+	f := NewFetcherWithConfig(config)
+	assert.Equal(t, int64(2), f.GetMetrics().RetriesRemaining)
+
+	var results []FetchResult
+	for result := range f.FetchURLs(context.Background(), []string{server.URL + "/1", server.URL + "/2", server.URL + "/3"}) {
+		results = append(results, result)
+	}
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, int64(5), hits.Load())
+	assert.Equal(t, int64(3), f.GetMetrics().Errors)
+	assert.Equal(t, int64(0), f.GetMetrics().RetriesRemaining)
+	assert.Equal(t, int64(-1), NewFetcher().GetMetrics().RetriesRemaining)
+}
+
+func TestParseDocument(t *testing.T) {
+	html := `<html><body>
+		<h1 id="caas-lead-header-undefined">Big   News</h1>
+		<div class="caas-subheadline">A short summary</div>
+		<div class="caas-body">
+			<p>First paragraph.</p>
+			<figure class="caas-figure"><p>caption</p></figure>
+			<p>Second
+			paragraph.</p>
+		</div>
+		<p>Outside the article</p>
+	</body></html>`
+
+	parsed, err := ParseDocument(strings.NewReader(html))
+	require.NoError(t, err)
+	assert.Equal(t, &ParsedDocument{
+		Title:   "Big News",
+		Subhead: "A short summary",
+		Body:    "First paragraph. Second paragraph.",
+	}, parsed)
+
+	content, _, err := parseContent(strings.NewReader(html))
+	require.NoError(t, err)
+	assert.Equal(t, "Big News A short summary First paragraph. Second paragraph.", content)
+}
+
+func TestNewFetcherWithConfig(t *testing.T) {
+	f := NewFetcherWithConfig(FetcherConfig{MaxRetries: 7})
+	assert.Equal(t, 7, f.config.MaxRetries)
+	assert.Equal(t, DefaultConfig().RequestsPerSecond, f.config.RequestsPerSecond)
+	assert.Equal(t, DefaultConfig().WorkerCount, f.config.WorkerCount)
+	assert.Equal(t, DefaultConfig().ResultBuffer, cap(f.results))
+	assert.Equal(t, 1, f.limiter.Burst())
+
+	f = NewFetcherWithConfig(FetcherConfig{Burst: 4})
+	assert.Equal(t, 4, f.limiter.Burst())
+
+	f = NewFetcherWithConfig(FetcherConfig{Burst: -2})
+	assert.Equal(t, DefaultConfig().Burst, f.limiter.Burst())
+
+	shared := rate.NewLimiter(rate.Limit(2), 5)
+	a := NewFetcherWithConfig(FetcherConfig{Limiter: shared, RequestsPerSecond: 50})
+	b := NewFetcherWithConfig(FetcherConfig{Limiter: shared})
+	assert.Same(t, shared, a.limiter)
+	assert.Same(t, a.limiter, b.limiter)
+}
+
+func TestNewFetcherWithConfigConnectionPool(t *testing.T) {
+	transport := NewFetcherWithConfig(FetcherConfig{WorkerCount: 20}).client.Transport.(*http.Transport)
+	assert.Equal(t, 100, transport.MaxIdleConns)
+	assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 0, transport.MaxConnsPerHost)
+
+	transport = NewFetcherWithConfig(FetcherConfig{MaxIdleConns: 10, MaxIdleConnsPerHost: 5, MaxConnsPerHost: 4}).client.Transport.(*http.Transport)
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 4, transport.MaxConnsPerHost)
+}
+
+func TestFetchURLsProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var calls [][2]int
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.OnProgress = func(done, total int) {
+		calls = append(calls, [2]int{done, total})
+	}
+	f := NewFetcherWithConfig(config)
+
+	urls := []string{server.URL + "/1", server.URL + "/2", server.URL + "/3", server.URL + "/4"}
+	for range f.FetchURLs(context.Background(), urls) {
+	}
+
+	assert.Equal(t, [][2]int{{1, 4}, {2, 4}, {3, 4}, {4, 4}}, calls)
 }
 
 func TestRateLimitHandling(t *testing.T) {
@@ -73,6 +453,104 @@ func TestRateLimitHandling(t *testing.T) {
 	assert.Contains(t, result.Content, "Success")
 }
 
+func TestFetcherPauseHoldsQueuedRequests(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>content</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 5
+	config.WorkerCount = 5
+	f := NewFetcherWithConfig(config)
+
+	urls := []string{server.URL + "/1", server.URL + "/2", server.URL + "/3", server.URL + "/4", server.URL + "/5"}
+	results := f.FetchURLs(context.Background(), urls)
+	<-results
+	f.Pause()
+	paused := requests.Load()
+	time.Sleep(600 * time.Millisecond)
+	assert.Equal(t, paused, requests.Load(), "URLs queued on the rate limiter wait for Resume")
+
+	f.Resume()
+	for range results {
+	}
+	assert.Equal(t, int64(5), requests.Load())
+}
+
+func TestFetcherPauseResume(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>content</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 1000
+	f := NewFetcherWithConfig(config)
+	f.Pause()
+	f.Pause()
+	assert.True(t, f.Paused())
+
+	results := f.FetchURLs(context.Background(), []string{server.URL + "/a", server.URL + "/b"})
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(0), requests.Load(), "no requests while paused")
+
+	f.Resume()
+	f.Resume()
+	assert.False(t, f.Paused())
+	count := 0
+	for range results {
+		count++
+	}
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func TestFetcherPauseCancel(t *testing.T) {
+	f := NewFetcher()
+	f.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := f.FetchURLs(ctx, []string{"http://example.invalid/"})
+	cancel()
+
+	for range results {
+	}
+	assert.Equal(t, StopCancelled, f.GetMetrics().StopReason)
+}
+
+func TestBackoffManagerOverlappingRateLimits(t *testing.T) {
+	b := newBackoffManager()
+	assert.Nil(t, b.wait())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			b.start(20 * time.Millisecond)
+		}()
+		go func() {
+			defer wg.Done()
+			b.wait()
+		}()
+	}
+	wg.Wait()
+
+	signal := b.wait()
+	require.NotNil(t, signal)
+	select {
+	case <-signal:
+	case <-time.After(time.Second):
+		t.Fatal("backoff never ended")
+	}
+	assert.Nil(t, b.wait())
+}
+
 func TestFetchFromFile(t *testing.T) {
 	content := "http://example.com/1\nhttp://example.com/2\n"
 	tmpfile, err := os.CreateTemp("", "urls-*.txt")
@@ -90,6 +568,76 @@ func TestFetchFromFile(t *testing.T) {
 	assert.Equal(t, "http://example.com/2", urls[1])
 }
 
+func TestFetchFromFileGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte("http://example.com/1\r\n\nhttp://example.com/2\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	dir := t.TempDir()
+	for _, name := range []string{"urls.txt.gz", "urls-no-extension"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+
+		urls, err := FetchFromFile(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, []string{"http://example.com/1", "http://example.com/2"}, urls, name)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.txt.gz")
+	require.NoError(t, os.WriteFile(corrupt, []byte("http://example.com/1\n"), 0644))
+	_, err = FetchFromFile(corrupt)
+	assert.Error(t, err)
+}
+
+func TestFetchFromFileLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	long := "http://example.com/?q=" + strings.Repeat("a", 100<<10)
+	require.NoError(t, os.WriteFile(path, []byte(long+"\nhttp://example.com/2\n"), 0644))
+
+	urls, err := FetchFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{long, "http://example.com/2"}, urls)
+}
+
+func TestFetchFromFileContextLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	content := "http://example.com/1\nhttp://example.com/2\nhttp://example.com/3\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	urls, err := FetchFromFileContext(context.Background(), path, int64(len(content)))
+	require.NoError(t, err)
+	assert.Len(t, urls, 3)
+
+	urls, err = FetchFromFileContext(context.Background(), path, 0)
+	require.NoError(t, err)
+	assert.Len(t, urls, 3)
+
+	_, err = FetchFromFileContext(context.Background(), path, 30)
+	var tooLarge *InputTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(31), tooLarge.Size)
+	assert.Equal(t, int64(30), tooLarge.Limit)
+	assert.Equal(t, 2, tooLarge.Lines)
+
+	gzipped := filepath.Join(t.TempDir(), "urls.txt.gz")
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, _ = zw.Write([]byte(strings.Repeat("http://example.com/page\n", 1000)))
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(gzipped, compressed.Bytes(), 0644))
+
+	_, err = FetchFromFileContext(context.Background(), gzipped, 1000)
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(1001), tooLarge.Size, "the limit applies to decompressed bytes")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = FetchFromFileContext(ctx, path, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestSaveToFile(t *testing.T) {
 	tmpfile, err := os.CreateTemp("", "content-*.txt")
 	require.NoError(t, err)
@@ -116,6 +664,31 @@ func TestGetMetrics(t *testing.T) {
 	assert.Equal(t, int64(3), metrics.RateLimited)
 }
 
+func TestGetMetricsStatusCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte("<html><body><p>Content</p></body></html>"))
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.MaxRetries = 1
+	f := NewFetcherWithConfig(config)
+
+	urls := []string{server.URL + "/a", server.URL + "/b", server.URL + "/missing", server.URL + "/broken"}
+	for range f.FetchURLs(context.Background(), urls) {
+	}
+
+	assert.Equal(t, map[int]int64{200: 2, 404: 1, 500: 1}, f.GetMetrics().StatusCodes)
+}
+
 func TestErrorHandling(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -0,0 +1,58 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrIdleTimeout is reported for requests aborted by IdleReadTimeout.
+var ErrIdleTimeout = errors.New("connection idle too long")
+
+// idleTimer cancels a request's context once no data has arrived for
+// timeout. Each read that returns data restarts the clock.
+type idleTimer struct {
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func withIdleTimeout(ctx context.Context, timeout time.Duration) (context.Context, *idleTimer) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	t := &idleTimer{ctx: ctx, cancel: cancel, timeout: timeout}
+	t.timer = time.AfterFunc(timeout, func() { cancel(ErrIdleTimeout) })
+	return ctx, t
+}
+
+func (t *idleTimer) stop() {
+	t.timer.Stop()
+	t.cancel(nil)
+}
+
+func (t *idleTimer) wrap(body io.ReadCloser) io.ReadCloser {
+	return &idleReader{ReadCloser: body, timer: t}
+}
+
+// cause replaces err with ErrIdleTimeout when the idle timer aborted the
+// request. It is safe to call on a nil timer.
+func (t *idleTimer) cause(err error) error {
+	if err != nil && t != nil && errors.Is(context.Cause(t.ctx), ErrIdleTimeout) {
+		return ErrIdleTimeout
+	}
+	return err
+}
+
+type idleReader struct {
+	io.ReadCloser
+	timer *idleTimer
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.timer.timer.Reset(r.timer.timeout)
+	}
+	return n, err
+}
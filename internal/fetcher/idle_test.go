@@ -0,0 +1,55 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchIdleReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.Write([]byte(`<div class="caas-body"><p>`))
+		flusher.Flush()
+
+		chunks := 5
+		pause := 20 * time.Millisecond
+		if r.URL.Path == "/stalled" {
+			chunks, pause = 1, 5*time.Second
+		}
+		for i := 0; i < chunks; i++ {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(pause):
+			}
+			w.Write([]byte("word "))
+			flusher.Flush()
+		}
+		w.Write([]byte(`</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.MaxRetries = 1
+	config.IdleReadTimeout = 100 * time.Millisecond
+	f := NewFetcherWithConfig(config)
+
+	start := time.Now()
+	results := map[string]FetchResult{}
+	for result := range f.FetchURLs(context.Background(), []string{server.URL + "/steady", server.URL + "/stalled"}) {
+		results[result.URL] = result
+	}
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+	require.Len(t, results, 2)
+	assert.Empty(t, results[server.URL+"/steady"].Error)
+	assert.Equal(t, "word word word word word", results[server.URL+"/steady"].Content)
+	assert.Contains(t, results[server.URL+"/stalled"].Error, ErrIdleTimeout.Error())
+}
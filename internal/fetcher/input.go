@@ -0,0 +1,92 @@
+package fetcher
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FetchURLsFromCSV reads the URLs in the given zero-based column. A first
+// row whose value isn't a URL is taken to be a header and skipped.
+func FetchURLsFromCSV(path string, column int) ([]string, error) {
+	if column < 0 {
+		return nil, fmt.Errorf("invalid CSV column %d", column)
+	}
+
+	content, err := readInputFile(path, MaxInputBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	var urls []string
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse CSV %s: %w", path, err)
+		}
+		if column >= len(record) {
+			return nil, fmt.Errorf("parse CSV %s: row %d has no column %d", path, row+1, column)
+		}
+
+		value := strings.TrimSpace(record[column])
+		if value == "" || (row == 0 && !strings.Contains(value, "://")) {
+			continue
+		}
+		urls = append(urls, value)
+	}
+	return urls, nil
+}
+
+// FetchURLsFromJSON reads the array of URL strings found by following the
+// dot-separated field names in jsonPath, e.g. "data.urls". An empty path
+// expects the document itself to be the array.
+func FetchURLsFromJSON(path, jsonPath string) ([]string, error) {
+	content, err := readInputFile(path, MaxInputBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		return nil, fmt.Errorf("parse JSON %s: %w", path, err)
+	}
+
+	if jsonPath != "" {
+		for _, field := range strings.Split(jsonPath, ".") {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("parse JSON %s: %q is not inside an object", path, field)
+			}
+			if value, ok = object[field]; !ok {
+				return nil, fmt.Errorf("parse JSON %s: field %q not found", path, field)
+			}
+		}
+	}
+
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("parse JSON %s: %q is not an array", path, jsonPath)
+	}
+
+	urls := make([]string, 0, len(items))
+	for i, item := range items {
+		url, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("parse JSON %s: item %d of %q is not a string", path, i, jsonPath)
+		}
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls, nil
+}
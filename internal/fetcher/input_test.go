@@ -0,0 +1,93 @@
+package fetcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchURLsFromCSV(t *testing.T) {
+	dir := t.TempDir()
+	withHeader := filepath.Join(dir, "with-header.csv")
+	require.NoError(t, os.WriteFile(withHeader, []byte("title,url\n\"Hello, world\",http://example.com/1\nEmpty,\nSecond,http://example.com/2\n"), 0644))
+	noHeader := filepath.Join(dir, "no-header.csv")
+	require.NoError(t, os.WriteFile(noHeader, []byte("http://example.com/1,a\nhttp://example.com/2,b\n"), 0644))
+
+	tests := []struct {
+		name    string
+		path    string
+		column  int
+		want    []string
+		wantErr bool
+	}{
+		{name: "header skipped", path: withHeader, column: 1, want: []string{"http://example.com/1", "http://example.com/2"}},
+		{name: "no header", path: noHeader, column: 0, want: []string{"http://example.com/1", "http://example.com/2"}},
+		{name: "column out of range", path: noHeader, column: 2, wantErr: true},
+		{name: "negative column", path: noHeader, column: -1, wantErr: true},
+		{name: "missing file", path: filepath.Join(dir, "missing.csv"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, err := FetchURLsFromCSV(tt.path, tt.column)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, urls)
+		})
+	}
+}
+
+func TestFetchURLsFromJSON(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "nested.json")
+	require.NoError(t, os.WriteFile(nested, []byte(`{"data": {"urls": ["http://example.com/1", " ", "http://example.com/2"], "count": 2}}`), 0644))
+	array := filepath.Join(dir, "array.json")
+	require.NoError(t, os.WriteFile(array, []byte(`["http://example.com/1"]`), 0644))
+
+	tests := []struct {
+		name     string
+		path     string
+		jsonPath string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "nested field", path: nested, jsonPath: "data.urls", want: []string{"http://example.com/1", "http://example.com/2"}},
+		{name: "top-level array", path: array, want: []string{"http://example.com/1"}},
+		{name: "missing field", path: nested, jsonPath: "data.links", wantErr: true},
+		{name: "not an array", path: nested, jsonPath: "data.count", wantErr: true},
+		{name: "field inside array", path: array, jsonPath: "urls", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, err := FetchURLsFromJSON(tt.path, tt.jsonPath)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, urls)
+		})
+	}
+}
+
+func TestReadInputFileLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.json")
+	content := `["http://example.com/1", "http://example.com/2"]`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	got, err := readInputFile(path, int64(len(content)))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(got))
+
+	_, err = readInputFile(path, 10)
+	var tooLarge *InputTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(11), tooLarge.Size)
+}
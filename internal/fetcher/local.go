@@ -0,0 +1,58 @@
+package fetcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProcessLocalDir parses the saved .html and .htm files directly inside dir
+// with the same extraction used for fetched pages. Each file yields one
+// FetchResult whose URL is the file's path; files that can't be read or
+// parsed are reported through Error.
+func ProcessLocalDir(dir string) (<-chan FetchResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".html" || ext == ".htm") {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	results := make(chan FetchResult, resultBuffer)
+	go func() {
+		defer close(results)
+
+		for _, path := range paths {
+			results <- parseLocalFile(path)
+		}
+	}()
+
+	return results, nil
+}
+
+func parseLocalFile(path string) FetchResult {
+	result := FetchResult{URL: path, FinalURL: path, FetchTime: time.Now()}
+
+	file, err := os.Open(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer file.Close()
+
+	if result.Content, result.Parsed, err = parseContent(file); err != nil {
+		result.Error = err.Error()
+	}
+	result.WordCount = len(strings.Fields(result.Content))
+	return result
+}
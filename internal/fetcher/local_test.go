@@ -0,0 +1,46 @@
+package fetcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessLocalDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.html":    `<h1 id="caas-lead-header-undefined">First</h1><div class="caas-body"><p>saved page</p></div>`,
+		"b.HTM":     `<div class="caas-body"><p>second page</p></div>`,
+		"notes.txt": "not html",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.html"), 0755))
+	unreadable := filepath.Join(dir, "c.html")
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing.html"), unreadable))
+
+	results, err := ProcessLocalDir(dir)
+	require.NoError(t, err)
+
+	var got []FetchResult
+	for result := range results {
+		got = append(got, result)
+	}
+
+	require.Len(t, got, 3)
+	assert.Equal(t, filepath.Join(dir, "a.html"), got[0].URL)
+	assert.Equal(t, "First saved page", got[0].Content)
+	assert.Equal(t, 3, got[0].WordCount)
+	assert.Equal(t, "First", got[0].Parsed.Title)
+	assert.Equal(t, filepath.Join(dir, "b.HTM"), got[1].URL)
+	assert.Equal(t, "second page", got[1].Content)
+	assert.Equal(t, unreadable, got[2].URL)
+	assert.NotEmpty(t, got[2].Error)
+
+	_, err = ProcessLocalDir(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
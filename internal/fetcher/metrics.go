@@ -0,0 +1,68 @@
+package fetcher
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ServeMetrics serves the fetcher's metrics in the Prometheus text format at
+// addr's /metrics path. Like http.ListenAndServe it blocks and always
+// returns a non-nil error, so run it in its own goroutine.
+func (f *Fetcher) ServeMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", f.MetricsHandler())
+	return http.ListenAndServe(addr, mux)
+}
+
+// MetricsHandler writes the fetcher's metrics in the Prometheus text format.
+func (f *Fetcher) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		f.writeMetrics(w)
+	})
+}
+
+func (f *Fetcher) writeMetrics(w io.Writer) {
+	counter := func(name, help string, value int64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+	}
+
+	counter("word_counter_urls_processed_total", "URLs fetched successfully.", f.metrics.processed.Load())
+	counter("word_counter_urls_failed_total", "URLs that failed after all retries.", f.metrics.errors.Load())
+	counter("word_counter_rate_limited_total", "Responses that signalled a rate limit.", f.metrics.rateLimited.Load())
+	counter("word_counter_urls_skipped_total", "Pages dropped for having too few words.", f.metrics.skipped.Load())
+	counter("word_counter_response_bytes_total", "Response body bytes read.", f.metrics.bytesRead.Load())
+
+	if remaining := f.quota.remaining(); remaining >= 0 {
+		fmt.Fprintf(w, "# HELP word_counter_quota_remaining Requests left in the current quota window.\n# TYPE word_counter_quota_remaining gauge\nword_counter_quota_remaining %d\n", remaining)
+	}
+
+	gauge := func(name, help string, value int64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, value)
+	}
+	gauge("word_counter_fetch_goroutines", "Fetch goroutines running now.", f.metrics.goroutines.Load())
+	gauge("word_counter_fetch_goroutines_peak", "Most fetch goroutines running at once.", f.metrics.peakGoroutines.Load())
+
+	fmt.Fprintf(w, "# HELP word_counter_requests_per_second Current request rate limit.\n# TYPE word_counter_requests_per_second gauge\nword_counter_requests_per_second %g\n", float64(f.limiter.Limit()))
+
+	name := "word_counter_request_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Time spent on HTTP requests, body included.\n# TYPE %s summary\n", name, name)
+	fmt.Fprintf(w, "%s_sum %g\n", name, time.Duration(f.metrics.requestNanos.Load()).Seconds())
+	fmt.Fprintf(w, "%s_count %d\n", name, f.metrics.requests.Load())
+
+	codes := f.metrics.statusCounts()
+	sorted := make([]int, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Ints(sorted)
+
+	name = "word_counter_responses_total"
+	fmt.Fprintf(w, "# HELP %s Responses by HTTP status code.\n# TYPE %s counter\n", name, name)
+	for _, code := range sorted {
+		fmt.Fprintf(w, "%s{code=\"%d\"} %d\n", name, code, codes[code])
+	}
+}
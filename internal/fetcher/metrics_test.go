@@ -0,0 +1,54 @@
+package fetcher
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	page := `<div class="caas-body"><p>hello</p></div>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	f := NewFetcherWithConfig(config)
+	for range f.FetchURLs(context.Background(), []string{server.URL + "/a", server.URL + "/missing"}) {
+	}
+
+	metricsServer := httptest.NewServer(f.MetricsHandler())
+	defer metricsServer.Close()
+
+	resp, err := http.Get(metricsServer.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+	for _, line := range []string{
+		"# TYPE word_counter_urls_processed_total counter",
+		"word_counter_urls_processed_total 2",
+		"word_counter_urls_failed_total 0",
+		"word_counter_response_bytes_total 41",
+		"word_counter_request_duration_seconds_count 2",
+		"word_counter_fetch_goroutines 0",
+		"# TYPE word_counter_fetch_goroutines_peak gauge",
+		`word_counter_responses_total{code="200"} 1`,
+		`word_counter_responses_total{code="404"} 1`,
+	} {
+		assert.Contains(t, string(body), line+"\n")
+	}
+}
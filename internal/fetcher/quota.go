@@ -0,0 +1,93 @@
+package fetcher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultQuotaWindow = 24 * time.Hour
+
+// QuotaExceededError is returned for URLs left once the request quota for
+// the current window is used up.
+type QuotaExceededError struct {
+	Limit   int
+	ResetAt time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("request quota of %d exceeded until %s", e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+// requestQuota allows limit requests per window, the window starting with
+// the first request made after the previous one ended.
+type requestQuota struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	used    int
+	resetAt time.Time
+}
+
+func newRequestQuota(limit int, window time.Duration) *requestQuota {
+	return &requestQuota{limit: limit, window: window}
+}
+
+// take uses up one request, or returns a QuotaExceededError when none are
+// left in the current window.
+func (q *requestQuota) take() error {
+	if q.limit <= 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.exceeded(); err != nil {
+		return err
+	}
+	q.used++
+	return nil
+}
+
+// check returns a QuotaExceededError when no requests are left in the
+// current window, without using one up, so URLs fail before waiting on the
+// rate limiter for a request they can't make.
+func (q *requestQuota) check() error {
+	if q.limit <= 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.exceeded()
+}
+
+func (q *requestQuota) exceeded() error {
+	q.roll(time.Now())
+	if q.used >= q.limit {
+		return &QuotaExceededError{Limit: q.limit, ResetAt: q.resetAt}
+	}
+	return nil
+}
+
+// remaining returns the requests left in the current window, or -1 when the
+// quota is disabled.
+func (q *requestQuota) remaining() int64 {
+	if q.limit <= 0 {
+		return -1
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.roll(time.Now())
+	return int64(q.limit - q.used)
+}
+
+func (q *requestQuota) roll(now time.Time) {
+	if !now.Before(q.resetAt) {
+		q.used = 0
+		q.resetAt = now.Add(q.window)
+	}
+}
@@ -0,0 +1,92 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestQuota(t *testing.T) {
+	q := newRequestQuota(2, 50*time.Millisecond)
+
+	assert.NoError(t, q.take())
+	assert.Equal(t, int64(1), q.remaining())
+	assert.NoError(t, q.take())
+
+	err := q.take()
+	var quotaErr *QuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, 2, quotaErr.Limit)
+	assert.Zero(t, q.remaining())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, int64(2), q.remaining())
+	assert.NoError(t, q.take())
+}
+
+func TestRequestQuotaDisabled(t *testing.T) {
+	q := newRequestQuota(0, time.Hour)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, q.take())
+	}
+	assert.Equal(t, int64(-1), q.remaining())
+}
+
+func TestFetchURLsQuota(t *testing.T) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`<div class="caas-body"><p>hello</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.WorkerCount = 1
+	config.Quota = 2
+	f := NewFetcherWithConfig(config)
+
+	urls := []string{server.URL + "/1", server.URL + "/2", server.URL + "/3", server.URL + "/4"}
+	var failed int
+	for result := range f.FetchURLs(context.Background(), urls) {
+		if result.Error != "" {
+			assert.Contains(t, result.Error, "quota of 2 exceeded")
+			failed++
+		}
+	}
+
+	assert.Equal(t, int64(2), hits.Load())
+	assert.Equal(t, 2, failed)
+	assert.Zero(t, f.GetMetrics().QuotaRemaining)
+}
+
+func TestFetchURLsQuotaSkipsRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<div class="caas-body"><p>hello</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 1
+	config.WorkerCount = 1
+	config.Quota = 1
+	f := NewFetcherWithConfig(config)
+
+	urls := []string{server.URL + "/1", server.URL + "/2", server.URL + "/3", server.URL + "/4"}
+	start := time.Now()
+	var failed int
+	for result := range f.FetchURLs(context.Background(), urls) {
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	assert.Equal(t, 3, failed)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "URLs over the quota shouldn't wait for the rate limiter")
+}
@@ -0,0 +1,214 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const userAgent = "word-counter"
+
+const robotsBlockedMessage = "blocked by robots.txt"
+
+// robotsFetchTimeout bounds the fetch of one host's robots.txt, which is
+// made apart from any URL's context since its rules outlive that URL.
+const robotsFetchTimeout = 30 * time.Second
+
+// disallowAll is the rule set for a host whose robots.txt failed with a
+// server error or couldn't be reached, as RFC 9309 asks.
+var disallowAll = robotsRules{disallow: []string{"/"}}
+
+type robotsCache struct {
+	client *http.Client
+	mu     sync.Mutex
+	hosts  map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	once  sync.Once
+	rules robotsRules
+}
+
+type robotsRules struct {
+	allow    []string
+	disallow []string
+}
+
+func newRobotsCache(client *http.Client) *robotsCache {
+	return &robotsCache{
+		client: client,
+		hosts:  make(map[string]*robotsEntry),
+	}
+}
+
+// allowed reports whether rawURL may be crawled. Each host's robots.txt is
+// fetched once. A missing one (4xx) leaves the host unrestricted, while a
+// server error or an unreachable host disallows everything.
+func (rc *robotsCache) allowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return true
+	}
+
+	origin := u.Scheme + "://" + u.Host
+	rc.mu.Lock()
+	entry, ok := rc.hosts[origin]
+	if !ok {
+		entry = &robotsEntry{}
+		rc.hosts[origin] = entry
+	}
+	rc.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.rules = rc.fetch(origin + "/robots.txt")
+	})
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return entry.rules.allows(path)
+}
+
+func (rc *robotsCache) fetch(robotsURL string) robotsRules {
+	ctx, cancel := context.WithTimeout(context.Background(), robotsFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return robotsRules{}
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return disallowAll
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll
+	case resp.StatusCode != http.StatusOK:
+		return robotsRules{}
+	}
+	return parseRobots(resp.Body, userAgent)
+}
+
+// parseRobots returns the rules of the group naming agent, falling back to
+// the "*" group when no group matches it.
+func parseRobots(r io.Reader, agent string) robotsRules {
+	agent = strings.ToLower(agent)
+
+	var matched, wildcard robotsRules
+	var foundMatch bool
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+			continue
+		}
+		if key != "allow" && key != "disallow" {
+			continue
+		}
+		inRules = true
+
+		for _, name := range groupAgents {
+			var rules *robotsRules
+			switch {
+			case name == "*":
+				rules = &wildcard
+			case strings.Contains(agent, name):
+				rules = &matched
+				foundMatch = true
+			default:
+				continue
+			}
+			if value == "" {
+				continue
+			}
+			if key == "allow" {
+				rules.allow = append(rules.allow, value)
+			} else {
+				rules.disallow = append(rules.disallow, value)
+			}
+		}
+	}
+
+	if foundMatch {
+		return matched
+	}
+	return wildcard
+}
+
+// allows applies the longest matching rule, with Allow winning ties.
+func (r robotsRules) allows(path string) bool {
+	longestAllow := longestMatch(r.allow, path)
+	longestDisallow := longestMatch(r.disallow, path)
+	return longestDisallow < 0 || longestAllow >= longestDisallow
+}
+
+func longestMatch(patterns []string, path string) int {
+	longest := -1
+	for _, pattern := range patterns {
+		if robotsMatch(pattern, path) && len(pattern) > longest {
+			longest = len(pattern)
+		}
+	}
+	return longest
+}
+
+// robotsMatch reports whether path matches a rule's pattern, a path prefix
+// in which '*' matches any characters and a final '$' anchors the end.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	last := parts[len(parts)-1]
+	if anchored {
+		return strings.HasSuffix(rest, last)
+	}
+	return strings.Contains(rest, last)
+}
@@ -0,0 +1,144 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := `
+User-agent: *
+Disallow: /private
+Allow: /private/open
+
+User-agent: other-bot
+User-agent: word-counter
+Disallow: /news # no news for us
+Allow: /news/sports
+`
+	tests := []struct {
+		name    string
+		agent   string
+		path    string
+		allowed bool
+	}{
+		{name: "matching group disallow", agent: userAgent, path: "/news/today", allowed: false},
+		{name: "matching group longer allow", agent: userAgent, path: "/news/sports/1", allowed: true},
+		{name: "matching group ignores wildcard", agent: userAgent, path: "/private", allowed: true},
+		{name: "wildcard disallow", agent: "someone-else", path: "/private/x", allowed: false},
+		{name: "wildcard allow", agent: "someone-else", path: "/private/open/x", allowed: true},
+		{name: "unlisted path", agent: "someone-else", path: "/news", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(robots), tt.agent)
+			assert.Equal(t, tt.allowed, rules.allows(tt.path))
+		})
+	}
+}
+
+func TestRobotsMatchWildcards(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		matches bool
+	}{
+		{name: "plain prefix", pattern: "/news", path: "/news/today", matches: true},
+		{name: "star in middle", pattern: "/*/print", path: "/news/print/1", matches: true},
+		{name: "star query", pattern: "/*?print=", path: "/a/b?print=1", matches: true},
+		{name: "star query missing", pattern: "/*?print=", path: "/a/b?page=1", matches: false},
+		{name: "anchored suffix", pattern: "/*.pdf$", path: "/docs/file.pdf", matches: true},
+		{name: "anchored suffix not at end", pattern: "/*.pdf$", path: "/docs/file.pdf?x=1", matches: false},
+		{name: "anchored exact", pattern: "/$", path: "/", matches: true},
+		{name: "anchored exact longer path", pattern: "/$", path: "/news", matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, robotsMatch(tt.pattern, tt.path))
+		})
+	}
+}
+
+func TestParseRobotsWildcardPrecedence(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent: *\nDisallow: /*.pdf$\nAllow: /public/*.pdf$\n"), userAgent)
+	assert.False(t, rules.allows("/docs/a.pdf"))
+	assert.True(t, rules.allows("/public/a.pdf"))
+	assert.True(t, rules.allows("/docs/a.html"))
+}
+
+func TestRobotsCacheFetchStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		allowed bool
+	}{
+		{name: "missing robots.txt", status: http.StatusNotFound, allowed: true},
+		{name: "server error", status: http.StatusServiceUnavailable, allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			rc := newRobotsCache(server.Client())
+			assert.Equal(t, tt.allowed, rc.allowed(server.URL+"/page"))
+		})
+	}
+}
+
+func TestRobotsCacheUnreachableDisallows(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	rc := newRobotsCache(http.DefaultClient)
+	assert.False(t, rc.allowed(url+"/page"))
+}
+
+func TestParseRobotsEmptyDisallow(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent: *\nDisallow:\n"), userAgent)
+	assert.True(t, rules.allows("/anything"))
+}
+
+func TestFetchURLsRespectRobots(t *testing.T) {
+	var robotsHits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsHits++
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="caas-body"><p>allowed page</p></div>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.WorkerCount = 1
+	config.RespectRobots = true
+	f := NewFetcherWithConfig(config)
+
+	urls := []string{server.URL + "/blocked/1", server.URL + "/open", server.URL + "/blocked/2"}
+	results := make(map[string]FetchResult)
+	for result := range f.FetchURLs(context.Background(), urls) {
+		results[result.URL] = result
+	}
+
+	require.Len(t, results, 3)
+	assert.Equal(t, robotsBlockedMessage, results[server.URL+"/blocked/1"].Error)
+	assert.Equal(t, robotsBlockedMessage, results[server.URL+"/blocked/2"].Error)
+	assert.Equal(t, "allowed page", results[server.URL+"/open"].Content)
+	assert.Equal(t, 1, robotsHits)
+}
@@ -0,0 +1,48 @@
+package fetcher
+
+import "sort"
+
+// ShardURLs splits urls into shards lists for separate processes, keeping
+// every URL of a host in the same shard so per-host rate limits still hold.
+// Hosts are assigned largest first to the shard with the fewest URLs, and
+// each shard keeps the URLs in their original order. A shards of one or
+// less returns all urls in a single shard.
+func ShardURLs(urls []string, shards int) [][]string {
+	if shards <= 1 {
+		return [][]string{urls}
+	}
+
+	byHost := make(map[string][]int)
+	var hosts []string
+	for i, url := range urls {
+		host := hostOf(url)
+		if _, seen := byHost[host]; !seen {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], i)
+	}
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return len(byHost[hosts[i]]) > len(byHost[hosts[j]])
+	})
+
+	loads := make([]int, shards)
+	assigned := make([]int, len(urls))
+	for _, host := range hosts {
+		least := 0
+		for s := range loads {
+			if loads[s] < loads[least] {
+				least = s
+			}
+		}
+		loads[least] += len(byHost[host])
+		for _, i := range byHost[host] {
+			assigned[i] = least
+		}
+	}
+
+	result := make([][]string, shards)
+	for i, url := range urls {
+		result[assigned[i]] = append(result[assigned[i]], url)
+	}
+	return result
+}
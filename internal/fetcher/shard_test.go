@@ -0,0 +1,61 @@
+package fetcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardURLs(t *testing.T) {
+	urls := []string{
+		"https://a.com/1", "https://b.com/1", "https://a.com/2", "https://c.com/1",
+		"https://a.com/3", "https://b.com/2", "https://d.com/1", "https://a.com/4",
+	}
+
+	tests := []struct {
+		name   string
+		shards int
+		want   [][]string
+	}{
+		{
+			name:   "single shard",
+			shards: 1,
+			want:   [][]string{urls},
+		},
+		{
+			name:   "two shards",
+			shards: 2,
+			want: [][]string{
+				{"https://a.com/1", "https://a.com/2", "https://a.com/3", "https://a.com/4"},
+				{"https://b.com/1", "https://c.com/1", "https://b.com/2", "https://d.com/1"},
+			},
+		},
+		{
+			name:   "three shards",
+			shards: 3,
+			want: [][]string{
+				{"https://a.com/1", "https://a.com/2", "https://a.com/3", "https://a.com/4"},
+				{"https://b.com/1", "https://b.com/2"},
+				{"https://c.com/1", "https://d.com/1"},
+			},
+		},
+		{
+			name:   "more shards than hosts",
+			shards: 6,
+			want: [][]string{
+				{"https://a.com/1", "https://a.com/2", "https://a.com/3", "https://a.com/4"},
+				{"https://b.com/1", "https://b.com/2"},
+				{"https://c.com/1"},
+				{"https://d.com/1"},
+				nil,
+				nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ShardURLs(urls, tt.shards))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package fetcher
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+)
+
+// URLLatency is the time from dispatching a URL to producing its result.
+type URLLatency struct {
+	URL     string
+	Latency time.Duration
+}
+
+// slowestTracker keeps the k slowest URLs seen. The heap is ordered fastest
+// first, so the root is the entry to evict when a slower URL arrives.
+type slowestTracker struct {
+	mutex sync.Mutex
+	k     int
+	heap  latencyHeap
+}
+
+func newSlowestTracker(k int) *slowestTracker {
+	return &slowestTracker{k: k}
+}
+
+func (t *slowestTracker) record(url string, latency time.Duration) {
+	if t.k <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.heap) < t.k {
+		heap.Push(&t.heap, URLLatency{URL: url, Latency: latency})
+		return
+	}
+	if latency > t.heap[0].Latency {
+		t.heap[0] = URLLatency{URL: url, Latency: latency}
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+// slowest returns the tracked URLs, slowest first.
+func (t *slowestTracker) slowest() []URLLatency {
+	t.mutex.Lock()
+	result := make([]URLLatency, len(t.heap))
+	copy(result, t.heap)
+	t.mutex.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Latency != result[j].Latency {
+			return result[i].Latency > result[j].Latency
+		}
+		return result[i].URL < result[j].URL
+	})
+	return result
+}
+
+type latencyHeap []URLLatency
+
+func (h latencyHeap) Len() int           { return len(h) }
+func (h latencyHeap) Less(i, j int) bool { return h[i].Latency < h[j].Latency }
+func (h latencyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *latencyHeap) Push(x any) {
+	*h = append(*h, x.(URLLatency))
+}
+
+func (h *latencyHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,76 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowestTracker(t *testing.T) {
+	tests := []struct {
+		name string
+		k    int
+		want []URLLatency
+	}{
+		{
+			name: "keeps the k slowest",
+			k:    2,
+			want: []URLLatency{{URL: "c", Latency: 5 * time.Second}, {URL: "a", Latency: 3 * time.Second}},
+		},
+		{
+			name: "fewer urls than k",
+			k:    10,
+			want: []URLLatency{
+				{URL: "c", Latency: 5 * time.Second},
+				{URL: "a", Latency: 3 * time.Second},
+				{URL: "b", Latency: time.Second},
+				{URL: "d", Latency: time.Millisecond},
+			},
+		},
+		{
+			name: "disabled",
+			k:    0,
+			want: []URLLatency{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newSlowestTracker(tt.k)
+			tracker.record("a", 3*time.Second)
+			tracker.record("b", time.Second)
+			tracker.record("c", 5*time.Second)
+			tracker.record("d", time.Millisecond)
+
+			assert.Equal(t, tt.want, tracker.slowest())
+		})
+	}
+}
+
+func TestFetchURLsSlowest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.Write([]byte("<html><body><p>hello</p></body></html>"))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestsPerSecond = 100
+	config.SlowestURLs = 1
+	f := NewFetcherWithConfig(config)
+
+	for range f.FetchURLs(context.Background(), []string{server.URL + "/fast", server.URL + "/slow"}) {
+	}
+
+	slowest := f.SlowestURLs()
+	require.Len(t, slowest, 1)
+	assert.Equal(t, server.URL+"/slow", slowest[0].URL)
+	assert.GreaterOrEqual(t, slowest[0].Latency, 50*time.Millisecond)
+}
@@ -0,0 +1,58 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchURLsTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<div class="caas-body"><p>secure</p></div>`))
+	}))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	tests := []struct {
+		name    string
+		config  func(*FetcherConfig)
+		wantErr bool
+	}{
+		{
+			name:    "verified by default",
+			config:  func(*FetcherConfig) {},
+			wantErr: true,
+		},
+		{
+			name:   "insecure skip verify",
+			config: func(c *FetcherConfig) { c.InsecureSkipVerify = true },
+		},
+		{
+			name:   "private CA",
+			config: func(c *FetcherConfig) { c.TLSConfig = &tls.Config{RootCAs: roots} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MaxRetries = 1
+			tt.config(&config)
+			result := <-NewFetcherWithConfig(config).FetchURLs(context.Background(), []string{server.URL})
+
+			if tt.wantErr {
+				assert.Contains(t, result.Error, "certificate")
+				return
+			}
+			assert.Empty(t, result.Error)
+			assert.Equal(t, "secure", result.Content)
+		})
+	}
+}
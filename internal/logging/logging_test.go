@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+	logger.Debug("hidden")
+	logger.Info("Saved results", "path", "out.json", "words", 3)
+	logger.Warn("Retrying", "err", errors.New("connection reset"))
+
+	out := buf.String()
+	assert.NotContains(t, out, "hidden")
+	assert.Contains(t, out, `level=INFO msg="Saved results" path=out.json words=3`)
+	assert.Contains(t, out, `level=WARN msg=Retrying err="connection reset"`)
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{in: "debug", want: slog.LevelDebug},
+		{in: "INFO", want: slog.LevelInfo},
+		{in: "warn", want: slog.LevelWarn},
+		{in: "error", want: slog.LevelError},
+		{in: "loud", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLevel(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	var _ Logger = Discard()
+	assert.False(t, Discard().Enabled(context.Background(), slog.LevelError))
+}
@@ -0,0 +1,14 @@
+package processor
+
+// CountContents counts the words of page contents already fetched, e.g. from
+// a cache, without starting a worker pool. Callers drop failed fetches first;
+// words are tokenized with opts against bank as in Tokenize.
+func CountContents(contents []string, bank *ValidWordBank, opts WordOptions) *SafeWordCounter {
+	counter := NewSafeWordCounter()
+	for _, content := range contents {
+		for _, word := range Tokenize(content, bank, opts) {
+			counter.Increment(word, 1)
+		}
+	}
+	return counter
+}
@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountContents(t *testing.T) {
+	contents := []string{"Hello world, hello again", "test the world"}
+
+	tests := []struct {
+		name string
+		bank *ValidWordBank
+		opts WordOptions
+		want []WordCount
+	}{
+		{
+			name: "with bank",
+			bank: ProcessValidWordBank([]string{"hello", "world", "test"}),
+			want: []WordCount{{Word: "hello", Count: 2}, {Word: "world", Count: 2}, {Word: "test", Count: 1}},
+		},
+		{
+			name: "without bank",
+			opts: WordOptions{StopWords: map[string]struct{}{"the": {}}},
+			want: []WordCount{{Word: "hello", Count: 2}, {Word: "world", Count: 2}, {Word: "again", Count: 1}, {Word: "test", Count: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CountContents(contents, tt.bank, tt.opts).GetTopWords(10))
+		})
+	}
+
+	assert.Empty(t, CountContents(nil, nil, WordOptions{}).GetTopWords(10))
+}
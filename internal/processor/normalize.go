@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ligatures expands the typographic ligatures pages use for plain letters.
+var ligatures = map[rune]string{
+	'\ufb00': "ff",
+	'\ufb01': "fi",
+	'\ufb02': "fl",
+	'\ufb03': "ffi",
+	'\ufb04': "ffl",
+	'\ufb05': "st",
+	'\ufb06': "st",
+}
+
+// NormalizeText rewrites the Unicode spacing and punctuation that would
+// otherwise hide words from Tokenize: exotic spaces, zero-width spaces and
+// dashes become spaces, invisible joiners and soft hyphens are removed,
+// smart quotes become ASCII quotes, and ligatures and fullwidth letters
+// become ASCII letters. ASCII text is returned unchanged.
+func NormalizeText(s string) string {
+	i := 0
+	for i < len(s) && s[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	b.WriteString(s[:i])
+	for _, r := range s[i:] {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteByte(byte(r))
+		case r == '\u200b', r == '\u2013', r == '\u2014', unicode.IsSpace(r):
+			b.WriteByte(' ')
+		case r == '\u00ad', r == '\u200c', r == '\u200d', r == '\u2060', r == '\ufeff':
+			// invisible inside a word, so the word stays whole
+		case r == '\u2018', r == '\u2019', r == '\u02bc':
+			b.WriteByte('\'')
+		case r == '\u201c', r == '\u201d':
+			b.WriteByte('"')
+		case r == '\u2010', r == '\u2011':
+			b.WriteByte('-')
+		case r >= '\uff01' && r <= '\uff5e':
+			b.WriteByte(byte(r - 0xFEE0)) // fullwidth forms of ASCII
+		case ligatures[r] != "":
+			b.WriteString(ligatures[r])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"ascii", "plain text", "plain text"},
+		{"nbsp", "one\u00a0two\u202fthree", "one two three"},
+		{"zero-width space", "one\u200btwo", "one two"},
+		{"joiners", "soft\u00adware\u200dand\ufeffmore", "softwareandmore"},
+		{"smart quotes", "\u201cdon\u2019t\u201d", "\"don't\""},
+		{"dashes", "state\u2011of\u2014art", "state-of art"},
+		{"ligatures", "\ufb01nd the \ufb02ow", "find the flow"},
+		{"fullwidth", "\uff37\uff4f\uff52\uff44", "Word"},
+		{"other letters kept", "caf\u00e9", "caf\u00e9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeText(tt.in))
+		})
+	}
+}
+
+func TestTokenizeNormalize(t *testing.T) {
+	content := "hello\u200bworld \ufb01nding\u00a0lost words don\u2019t"
+
+	assert.Equal(t, []string{"helloworld", "nding", "lost", "words", "dont"},
+		Tokenize(content, nil, WordOptions{}))
+	assert.Equal(t, []string{"hello", "world", "finding", "lost", "words", "don't"},
+		Tokenize(content, nil, WordOptions{Normalize: true, KeepApostrophes: true}))
+}
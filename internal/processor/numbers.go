@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NumericCounter counts numeric tokens such as years and quantities, which
+// the word tokenizer drops, apart from words so both can be ranked.
+type NumericCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewNumericCounter() *NumericCounter {
+	return &NumericCounter{counts: make(map[string]int)}
+}
+
+// Add counts the numbers in content.
+func (c *NumericCounter) Add(content string) {
+	numbers := Numbers(content)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, number := range numbers {
+		c.counts[number]++
+	}
+}
+
+func (c *NumericCounter) GetCount(number string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[number]
+}
+
+// TopNumbers returns the n most frequent numbers, ties ordered as strings.
+func (c *NumericCounter) TopNumbers(n int) []WordCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return rankWordCounts(c.counts, n, false)
+}
+
+// Numbers returns the whitespace-separated tokens of content that are purely
+// numeric once surrounding punctuation is trimmed, in order. Digits may be
+// grouped by single commas or points, as in "1,000" or "3.5", but tokens
+// mixing letters and digits like "mp3" aren't numbers.
+func Numbers(content string) []string {
+	var numbers []string
+	for _, field := range strings.Fields(content) {
+		token := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if isNumber(token) {
+			numbers = append(numbers, token)
+		}
+	}
+	return numbers
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+		case (c == ',' || c == '.') && i > 0 && i < len(s)-1 && s[i-1] >= '0' && s[i-1] <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"empty", "", nil},
+		{"words only", "technology percent", nil},
+		{"year and quantity", "In 2024, sales rose 12 percent.", []string{"2024", "12"}},
+		{"grouped digits", "1,000 people paid $3.50 (2.5%)", []string{"1,000", "3.50", "2.5"}},
+		{"mixed tokens", "mp3 4k 2024-25 v2.0 1,,0", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Numbers(tt.content))
+		})
+	}
+}
+
+func TestNumericCounter(t *testing.T) {
+	counter := NewNumericCounter()
+	counter.Add("In 2024 the 5 percent rise")
+	counter.Add("2024 and 2023, up 5 and 10")
+
+	assert.Equal(t, 2, counter.GetCount("2024"))
+	assert.Equal(t, 0, counter.GetCount("percent"))
+	assert.Equal(t, []WordCount{{Word: "2024", Count: 2}, {Word: "5", Count: 2}, {Word: "10", Count: 1}}, counter.TopNumbers(3))
+}
@@ -1,58 +1,538 @@
 package processor
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"math"
+	"os"
+	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/shuaibbapputty/word-counter/internal/logging"
 )
 
+// ValidWordBank is the set of words that are counted. It is safe for
+// concurrent use, so words can be added or removed while workers read it.
 type ValidWordBank struct {
-	words map[string]struct{}
+	mu      sync.RWMutex
+	words   map[string]string // each word maps to itself so lookups can reuse the key
+	options WordOptions
+}
+
+// WordOptions controls how words are validated for the bank and tokenized
+// from content. The zero value keeps lowercased ASCII letters only, at least
+// three of them per word.
+type WordOptions struct {
+	KeepApostrophes bool // keep one internal apostrophe so "don't" isn't counted as "dont"
+	Hyphens         HyphenMode
+	CaseSensitive   bool                // keep capitals so "Apple" and "apple" are counted apart
+	AllowDigits     bool                // keep digits inside words like "mp3"; a word still needs a letter
+	MinLength       int                 // shortest word kept, defaultMinLength when zero
+	MaxLength       int                 // longest word kept, unlimited when zero
+	StopWords       map[string]struct{} // words never returned by Tokenize
+	Filter          *regexp.Regexp      // when set, Tokenize keeps only the words it matches
+
+	// Normalize runs NormalizeText over content before tokenizing, so words
+	// split by zero-width spaces or written with ligatures aren't lost.
+	Normalize bool
+
+	// UnicodeLetters keeps non-ASCII letters in words, lowercasing both bank
+	// and content with unicode.ToLower, so "Café" in the bank matches "CAFÉ"
+	// in content. Otherwise both fold ASCII only and drop other letters.
+	UnicodeLetters bool
 }
 
+const defaultMinLength = 3
+
+// allowsLength reports whether a word of n bytes is within the length limits.
+func (o *WordOptions) allowsLength(n int) bool {
+	minLength := o.MinLength
+	if minLength <= 0 {
+		minLength = defaultMinLength
+	}
+	return n >= minLength && (o.MaxLength <= 0 || n <= o.MaxLength)
+}
+
+// HyphenMode decides what happens to hyphens inside words such as
+// "state-of-the-art".
+type HyphenMode int
+
+const (
+	HyphenDrop  HyphenMode = iota // remove hyphens, giving "stateoftheart"
+	HyphenSplit                   // treat hyphens as word breaks, giving "state", "of", "the", "art"
+	HyphenKeep                    // keep hyphens inside the word, giving "state-of-the-art"
+)
+
 func ProcessValidWordBank(rawWords []string) *ValidWordBank {
-	vwb := &ValidWordBank{
-		words: make(map[string]struct{}),
+	return ProcessValidWordBankWithOptions(rawWords, WordOptions{})
+}
+
+// ProcessValidWordBankCtx is ProcessValidWordBank for dictionaries big enough
+// that building one should stop when ctx is cancelled.
+func ProcessValidWordBankCtx(ctx context.Context, rawWords []string) (*ValidWordBank, error) {
+	return buildWordBank(ctx, rawWords, WordOptions{})
+}
+
+// ProcessValidWordBankWithOptions builds a bank whose words, and the content
+// later checked against it, follow opts.
+func ProcessValidWordBankWithOptions(rawWords []string, opts WordOptions) *ValidWordBank {
+	vwb, _ := buildWordBank(context.Background(), rawWords, opts)
+	return vwb
+}
+
+// bankCancelCheck is how many words are validated between checks of ctx.
+const bankCancelCheck = 4096
+
+// parallelBankThreshold is the dictionary size below which banks are built
+// serially, since a typical word list validates faster than goroutines start.
+const parallelBankThreshold = 1 << 16
+
+func buildWordBank(ctx context.Context, rawWords []string, opts WordOptions) (*ValidWordBank, error) {
+	shards := runtime.GOMAXPROCS(0)
+	if len(rawWords) < parallelBankThreshold || shards < 2 {
+		shards = 1
+	}
+
+	words, err := buildWordSet(ctx, rawWords, opts, shards)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidWordBank{words: words, options: opts}, nil
+}
+
+// buildWordSet validates rawWords in shards concurrently, each into its own
+// set, and merges the sets once all are done.
+func buildWordSet(ctx context.Context, rawWords []string, opts WordOptions, shards int) (map[string]string, error) {
+	if shards <= 1 {
+		return buildWordShard(ctx, rawWords, opts)
 	}
 
-	for _, word := range rawWords {
-		word = strings.ToLower(word)
-		if len(word) >= 3 && isAlpha(word) {
-			vwb.words[word] = struct{}{}
+	sets := make([]map[string]string, shards)
+	errs := make([]error, shards)
+	size := (len(rawWords) + shards - 1) / shards
+	var wg sync.WaitGroup
+	for i := range sets {
+		lo, hi := min(i*size, len(rawWords)), min((i+1)*size, len(rawWords))
+		wg.Add(1)
+		go func(i int, shard []string) {
+			defer wg.Done()
+			sets[i], errs[i] = buildWordShard(ctx, shard, opts)
+		}(i, rawWords[lo:hi])
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
 	}
+	merged := sets[0]
+	for _, set := range sets[1:] {
+		for word := range set {
+			merged[word] = word
+		}
+	}
+	return merged, nil
+}
 
-	return vwb
+func buildWordShard(ctx context.Context, rawWords []string, opts WordOptions) (map[string]string, error) {
+	words := make(map[string]string)
+	for i, word := range rawWords {
+		if i%bankCancelCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if word, ok := normalizeBankWord(word, opts); ok {
+			words[word] = word
+		}
+	}
+	return words, nil
+}
+
+func normalizeBankWord(word string, opts WordOptions) (string, bool) {
+	if opts.KeepApostrophes {
+		word = strings.ReplaceAll(word, "’", "'")
+	}
+	folded := asciiLower(word)
+	if opts.UnicodeLetters {
+		folded = strings.ToLower(word)
+	}
+	if !opts.CaseSensitive {
+		word = folded
+	}
+	if !opts.allowsLength(len(word)) {
+		return word, false
+	}
+
+	isWord := isAlpha
+	if opts.AllowDigits {
+		if !containsLetter(folded) {
+			return word, false
+		}
+		isWord = isAlnum
+	}
+	if opts.UnicodeLetters {
+		isWord = func(s string) bool { return isLetterWord(s, opts.AllowDigits) }
+	}
+	validPart := isWord
+	if opts.KeepApostrophes {
+		validPart = func(s string) bool { return contractionOf(s, isWord) }
+	}
+
+	parts := []string{folded}
+	if opts.Hyphens == HyphenKeep {
+		parts = strings.Split(folded, "-")
+	}
+	for _, part := range parts {
+		if part == "" || !validPart(part) {
+			return word, false
+		}
+	}
+	return word, true
+}
+
+// ProcessValidWordBankFromFiles builds one bank from several newline-separated
+// word files, so a general dictionary can be combined with domain glossaries.
+func ProcessValidWordBankFromFiles(paths ...string) (*ValidWordBank, error) {
+	return ProcessValidWordBankFromFilesDelimited("\n", paths...)
+}
+
+// ProcessValidWordBankFromFilesDelimited is ProcessValidWordBankFromFiles
+// for word files separated by delimiter, e.g. "," for comma-separated lists,
+// or by any whitespace when delimiter is empty. Whitespace around words,
+// including the \r of Windows line endings, and a UTF-8 byte order mark
+// are ignored.
+func ProcessValidWordBankFromFilesDelimited(delimiter string, paths ...string) (*ValidWordBank, error) {
+	var rawWords []string
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read word bank %s: %w", path, err)
+		}
+		rawWords = append(rawWords, splitWordList(string(content), delimiter)...)
+	}
+
+	return ProcessValidWordBank(rawWords), nil
+}
+
+func splitWordList(content, delimiter string) []string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	if delimiter == "" {
+		return strings.Fields(content)
+	}
+
+	var words []string
+	for _, word := range strings.Split(content, delimiter) {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
 }
 
 func (vwb *ValidWordBank) IsValid(word string) bool {
+	vwb.mu.RLock()
 	_, exists := vwb.words[word]
+	vwb.mu.RUnlock()
 	return exists
 }
 
+// Len returns the number of words in the bank.
+func (vwb *ValidWordBank) Len() int {
+	vwb.mu.RLock()
+	defer vwb.mu.RUnlock()
+	return len(vwb.words)
+}
+
+// IsValidBytes is IsValid for a byte slice. The compiler turns the map
+// index on string(b) into a lookup without allocating a string.
+func (vwb *ValidWordBank) IsValidBytes(b []byte) bool {
+	_, exists := vwb.lookup(b)
+	return exists
+}
+
+// lookup returns the bank's copy of the word in b, so callers keeping the
+// word needn't allocate a string of their own.
+func (vwb *ValidWordBank) lookup(b []byte) (string, bool) {
+	vwb.mu.RLock()
+	word, exists := vwb.words[string(b)]
+	vwb.mu.RUnlock()
+	return word, exists
+}
+
+// AddWord validates word like the bank loader does and reports whether it was
+// newly added.
+func (vwb *ValidWordBank) AddWord(word string) bool {
+	word, ok := normalizeBankWord(word, vwb.options)
+	if !ok {
+		return false
+	}
+
+	vwb.mu.Lock()
+	defer vwb.mu.Unlock()
+
+	if _, exists := vwb.words[word]; exists {
+		return false
+	}
+	vwb.words[word] = word
+	return true
+}
+
+// RemoveWord reports whether word was present and has been removed.
+func (vwb *ValidWordBank) RemoveWord(word string) bool {
+	word, ok := normalizeBankWord(word, vwb.options)
+	if !ok {
+		return false
+	}
+
+	vwb.mu.Lock()
+	defer vwb.mu.Unlock()
+
+	if _, exists := vwb.words[word]; !exists {
+		return false
+	}
+	delete(vwb.words, word)
+	return true
+}
+
+// ProcessContent tokenizes content using the bank's own options. A nil bank
+// keeps every word, which helps when exploring a vocabulary before building
+// a dictionary.
 func ProcessContent(content string, wordBank *ValidWordBank) []string {
-	words := strings.Fields(content)
-	validWords := make([]string, 0, len(words))
+	var opts WordOptions
+	if wordBank != nil {
+		opts = wordBank.options
+	}
+	return Tokenize(content, wordBank, opts)
+}
+
+// Tokenize returns the words of content that are in wordBank, or every word
+// when wordBank is nil, in order. Words are split on the same whitespace as
+// strings.Fields, in a single pass without building the slice of fields.
+// ProcessContent uses it with the bank's own options; other options suit
+// text from outside the crawler, e.g. to drop stop words.
+func Tokenize(content string, wordBank *ValidWordBank, opts WordOptions) []string {
+	return tokenize(content, wordBank, opts, nil)
+}
+
+// TokenizeRuns returns the words Tokenize would, split into runs of words
+// that were next to each other in content. A word dropped by the bank, the
+// length limits, StopWords or Filter ends a run, so phrases built within runs
+// never join words that were apart.
+func TokenizeRuns(content string, wordBank *ValidWordBank, opts WordOptions) [][]string {
+	var breaks []int
+	words := tokenize(content, wordBank, opts, &breaks)
+
+	runs := make([][]string, 0, len(breaks)+1)
+	start := 0
+	for _, end := range append(breaks, len(words)) {
+		if end > start {
+			runs = append(runs, words[start:end])
+		}
+		start = end
+	}
+	return runs
+}
+
+// tokenize is Tokenize, also appending to breaks, when not nil, the index in
+// the result of each word that follows a dropped one.
+func tokenize(content string, wordBank *ValidWordBank, opts WordOptions, breaks *[]int) []string {
+	if opts.Normalize {
+		content = NormalizeText(content)
+	}
+
+	validWords := make([]string, 0, len(content)/avgWordBytes)
 	buf := make([]byte, 0, 32)
 
-	for _, word := range words {
-		buf = buf[:0]
-		for i := 0; i < len(word); i++ {
-			c := word[i]
-			if c >= 'A' && c <= 'Z' {
+	for i := 0; i < len(content); {
+		c := content[i]
+		if c < utf8.RuneSelf {
+			i++
+			switch {
+			case c >= 'A' && c <= 'Z' && !opts.CaseSensitive:
 				buf = append(buf, c+32) // to lowercase
-			} else if c >= 'a' && c <= 'z' {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9' && opts.AllowDigits:
 				buf = append(buf, c)
+			case c == '\'' && opts.KeepApostrophes:
+				buf = appendApostrophe(buf)
+			case c == '-' && opts.Hyphens == HyphenKeep:
+				buf = appendHyphen(buf)
+			case asciiSpace[c] == 1, c == '-' && opts.Hyphens == HyphenSplit:
+				validWords = appendWordOrBreak(validWords, buf, wordBank, &opts, breaks)
+				buf = buf[:0]
+			}
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(content[i:])
+		i += size
+		switch {
+		case r == '\u2019' && opts.KeepApostrophes:
+			buf = appendApostrophe(buf)
+		case r == '\u2010' && opts.Hyphens == HyphenKeep:
+			buf = appendHyphen(buf)
+		case opts.UnicodeLetters && unicode.IsLetter(r):
+			if !opts.CaseSensitive {
+				r = unicode.ToLower(r)
 			}
+			buf = utf8.AppendRune(buf, r)
+		case unicode.IsSpace(r), r == '\u2010' && opts.Hyphens == HyphenSplit:
+			validWords = appendWordOrBreak(validWords, buf, wordBank, &opts, breaks)
+			buf = buf[:0]
 		}
+	}
+
+	return appendWordOrBreak(validWords, buf, wordBank, &opts, breaks)
+}
 
-		if len(buf) >= 3 && wordBank.IsValid(string(buf)) {
-			validWords = append(validWords, string(buf))
+// appendWordOrBreak is appendValidWord, recording a break when a non-empty
+// token is dropped.
+func appendWordOrBreak(validWords []string, buf []byte, wordBank *ValidWordBank, opts *WordOptions, breaks *[]int) []string {
+	n := len(validWords)
+	validWords = appendValidWord(validWords, buf, wordBank, opts)
+	if breaks != nil && len(buf) > 0 && len(validWords) == n {
+		if last := len(*breaks) - 1; last < 0 || (*breaks)[last] != n {
+			*breaks = append(*breaks, n)
 		}
 	}
 	return validWords
 }
 
+// parallelThreshold is the content size below which ProcessContentParallel
+// tokenizes serially, since splitting a typical article costs more than it saves.
+const parallelThreshold = 1 << 20
+
+// ProcessContentParallel returns the same words as ProcessContent, but splits
+// content over a megabyte into chunks at whitespace and tokenizes them
+// concurrently.
+func ProcessContentParallel(content string, wordBank *ValidWordBank) []string {
+	numChunks := runtime.GOMAXPROCS(0)
+	if len(content) < parallelThreshold || numChunks < 2 {
+		return ProcessContent(content, wordBank)
+	}
+
+	chunks := splitAtSpace(content, numChunks)
+	words := make([][]string, len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk string) {
+			defer wg.Done()
+			words[i] = ProcessContent(chunk, wordBank)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, w := range words {
+		total += len(w)
+	}
+	merged := make([]string, 0, total)
+	for _, w := range words {
+		merged = append(merged, w...)
+	}
+	return merged
+}
+
+// splitAtSpace cuts content into about n pieces, moving each cut forward to
+// the next ASCII whitespace so no word is split.
+func splitAtSpace(content string, n int) []string {
+	chunks := make([]string, 0, n)
+	size := len(content) / n
+	for len(content) > 0 {
+		if len(chunks) == n-1 || len(content) <= size {
+			return append(chunks, content)
+		}
+		cut := size
+		for cut < len(content) && (content[cut] >= utf8.RuneSelf || asciiSpace[content[cut]] == 0) {
+			cut++
+		}
+		chunks = append(chunks, content[:cut])
+		content = content[cut:]
+	}
+	return chunks
+}
+
+// avgWordBytes approximates an English word plus its separator, used to size
+// the result up front.
+const avgWordBytes = 6
+
+var asciiSpace = [utf8.RuneSelf]uint8{'\t': 1, '\n': 1, '\v': 1, '\f': 1, '\r': 1, ' ': 1}
+
+// appendApostrophe keeps only the first apostrophe that follows a letter.
+func appendApostrophe(buf []byte) []byte {
+	if len(buf) == 0 || bytes.IndexByte(buf, '\'') >= 0 {
+		return buf
+	}
+	return append(buf, '\'')
+}
+
+// appendHyphen keeps a hyphen only directly after a letter, so runs of
+// hyphens collapse to one.
+func appendHyphen(buf []byte) []byte {
+	if n := len(buf); n == 0 || buf[n-1] == '-' || buf[n-1] == '\'' {
+		return buf
+	}
+	return append(buf, '-')
+}
+
+func appendValidWord(validWords []string, buf []byte, wordBank *ValidWordBank, opts *WordOptions) []string {
+	for n := len(buf); n > 0 && (buf[n-1] == '\'' || buf[n-1] == '-'); n-- {
+		buf = buf[:n-1]
+	}
+	if !opts.allowsLength(len(buf)) || (opts.AllowDigits && !containsLetter(buf)) {
+		return validWords
+	}
+
+	// a bank word reuses the bank's string, so only bankless words allocate
+	var word string
+	if wordBank != nil {
+		var ok bool
+		if word, ok = wordBank.lookup(buf); !ok {
+			return validWords
+		}
+	} else {
+		word = string(buf)
+	}
+	if _, stop := opts.StopWords[word]; stop {
+		return validWords
+	}
+	if opts.Filter != nil && !opts.Filter.MatchString(word) {
+		return validWords
+	}
+	return append(validWords, word)
+}
+
+// isContraction reports whether s is lowercase letters with at most one
+// apostrophe between them.
+func isContraction(s string) bool {
+	return contractionOf(s, isAlpha)
+}
+
+// contractionOf is isContraction with isWord deciding what the parts around
+// the apostrophe may contain.
+func contractionOf(s string, isWord func(string) bool) bool {
+	i := strings.IndexByte(s, '\'')
+	if i == -1 {
+		return isWord(s)
+	}
+	return i > 0 && i < len(s)-1 && isWord(s[:i]) && isWord(s[i+1:])
+}
+
 func isAlpha(s string) bool {
 	for _, r := range s {
 		if r < 'a' || r > 'z' {
@@ -62,91 +542,874 @@ func isAlpha(s string) bool {
 	return true
 }
 
+// isLetterWord reports whether s is Unicode letters, or letters and ASCII
+// digits when digits is set.
+func isLetterWord(s string, digits bool) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && (!digits || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// asciiLower lowercases the ASCII letters of s, the folding Tokenize applies
+// to content.
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + 32
+		}
+		return r
+	}, s)
+}
+
+// isAlnum reports whether s is lowercase letters and digits.
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// containsLetter reports whether s has an ASCII letter or any non-ASCII
+// byte, which words only hold as part of a letter.
+func containsLetter[T string | []byte](s T) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c >= 'a' && c <= 'z' || c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildNGrams joins each run of n contiguous words with a space. An n of 1
+// or less returns the words unchanged. Words must have been adjacent in the
+// text, e.g. one run from TokenizeRuns.
+func BuildNGrams(words []string, n int) []string {
+	if n <= 1 {
+		return words
+	}
+	if len(words) < n {
+		return nil
+	}
+
+	grams := make([]string, 0, len(words)-n+1)
+	for i := 0; i+n <= len(words); i++ {
+		grams = append(grams, strings.Join(words[i:i+n], " "))
+	}
+	return grams
+}
+
+var (
+	ErrPoolShuttingDown = errors.New("worker pool is shutting down")
+	ErrPoolCloseTimeout = errors.New("timed out waiting for workers to finish")
+	ErrWorkerPanic      = errors.New("worker panicked")
+)
+
+// ProcessingError reports a document the worker pool failed to process.
+type ProcessingError struct {
+	Input string
+	Err   error
+}
+
+func (e *ProcessingError) Error() string {
+	input := e.Input
+	if len(input) > 50 {
+		input = input[:50] + "..."
+	}
+	return fmt.Sprintf("process %q: %v", input, e.Err)
+}
+
+func (e *ProcessingError) Unwrap() error {
+	return e.Err
+}
+
+type WorkerPoolOptions struct {
+	NGram        int // number of contiguous words counted as one term, 1 counts single words
+	JobBuffer    int // defaults to twice the number of workers
+	ResultBuffer int // defaults to twice the number of workers
+
+	// AggregatePerWorker makes each worker keep a running count and emit it
+	// once on Close, instead of one map per document. Per-document consumers
+	// such as DocumentFrequencyCounter need the default streaming mode.
+	AggregatePerWorker bool
+
+	// Weights multiply the counts of words in each part of documents given
+	// to SubmitDocument.
+	Weights Weights
+
+	// Tokenizer splits documents into words, DefaultTokenizer over the
+	// pool's word bank when nil.
+	Tokenizer Tokenizer
+
+	// Sink receives each job's word counts directly from the workers. When
+	// nil they are sent to the Results channel instead.
+	Sink ResultSink
+
+	// Logger receives worker panics. Logging is off when nil.
+	Logger logging.Logger
+}
+
+// ResultSink receives the word counts of processed jobs. Workers call Accept
+// concurrently, so implementations must be safe for concurrent use.
+type ResultSink interface {
+	Accept(wordCounts map[string]int)
+}
+
+// ResultSinkFunc adapts a function to the ResultSink interface.
+type ResultSinkFunc func(wordCounts map[string]int)
+
+func (f ResultSinkFunc) Accept(wordCounts map[string]int) {
+	f(wordCounts)
+}
+
+// SourceSink is a ResultSink that also learns where each job came from. A
+// pool whose Sink implements it calls AcceptFrom with the source given to
+// SubmitFrom or Document.Source, unless AggregatePerWorker merges jobs.
+type SourceSink interface {
+	ResultSink
+	AcceptFrom(source string, wordCounts map[string]int)
+}
+
+// SourceSinkFunc adapts a function to the SourceSink interface. Accept
+// passes an empty source.
+type SourceSinkFunc func(source string, wordCounts map[string]int)
+
+func (f SourceSinkFunc) Accept(wordCounts map[string]int) {
+	f("", wordCounts)
+}
+
+func (f SourceSinkFunc) AcceptFrom(source string, wordCounts map[string]int) {
+	f(source, wordCounts)
+}
+
+// channelSink is the default sink, feeding the pool's Results channel until
+// the pool's context is cancelled.
+type channelSink struct {
+	pool *WorkerPool
+}
+
+func (s channelSink) Accept(wordCounts map[string]int) {
+	select {
+	case s.pool.results <- wordCounts:
+	case <-s.pool.ctx.Done():
+	}
+}
+
+// Tokenizer splits content into the words to count, so the pool can count
+// tokens other than the default ASCII words, e.g. numbers or CJK text.
+type Tokenizer interface {
+	Tokenize(content string) []string
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(content string) []string
+
+func (f TokenizerFunc) Tokenize(content string) []string {
+	return f(content)
+}
+
+// DefaultTokenizer keeps the words of content found in Bank, like
+// ProcessContent, splitting very large documents across goroutines.
+type DefaultTokenizer struct {
+	Bank *ValidWordBank
+}
+
+func (t DefaultTokenizer) Tokenize(content string) []string {
+	return ProcessContentParallel(content, t.Bank)
+}
+
+// TokenizeRuns splits content like ProcessContent, into runs of adjacent
+// words as TokenizeRuns does.
+func (t DefaultTokenizer) TokenizeRuns(content string) [][]string {
+	var opts WordOptions
+	if t.Bank != nil {
+		opts = t.Bank.options
+	}
+	return TokenizeRuns(content, t.Bank, opts)
+}
+
+// RunTokenizer is a Tokenizer that can also tell where words were dropped,
+// so the pool builds n-grams only from words that were adjacent.
+type RunTokenizer interface {
+	Tokenizer
+	TokenizeRuns(content string) [][]string
+}
+
+// Weights is how many times a word counts in each part of a document. Zero
+// fields count once.
+type Weights struct {
+	Title   int
+	Subhead int
+	Body    int
+}
+
+// Document is an article split into the parts that Weights apply to.
+type Document struct {
+	Title   string
+	Subhead string
+	Body    string
+
+	Source string // where the document came from, e.g. its URL, for a SourceSink
+}
+
+// job is either plain content or, when doc is set, a document to weight.
+type job struct {
+	content string
+	doc     *Document
+	source  string
+}
+
+type PoolMetrics struct {
+	JobsProcessed     int64
+	WordsProcessed    int64
+	ProcessingTime    time.Duration // summed over workers, so it can exceed ElapsedTime
+	AvgProcessingTime time.Duration
+	ElapsedTime       time.Duration // wall-clock time from Start to the last finished job
+}
+
+type poolMetrics struct {
+	jobsProcessed  atomic.Int64
+	wordsProcessed atomic.Int64
+	processingTime atomic.Int64
+	started        atomic.Int64
+	lastFinished   atomic.Int64
+}
+
 type WorkerPool struct {
-	wordBank   *ValidWordBank
 	numWorkers int
-	jobs       chan string
+	options    WorkerPoolOptions
+	ctx        context.Context
+	process    func(content string) (map[string]int, error)
+	jobs       chan job
 	results    chan map[string]int
+	errors     chan error
+	metrics    *poolMetrics
 	wg         *sync.WaitGroup
 }
 
 func NewWorkerPool(wordBank *ValidWordBank, numWorkers int) *WorkerPool {
+	return NewWorkerPoolWithOptions(wordBank, numWorkers, WorkerPoolOptions{})
+}
+
+func NewWorkerPoolWithOptions(wordBank *ValidWordBank, numWorkers int, opts WorkerPoolOptions) *WorkerPool {
 	if numWorkers <= 0 {
 		numWorkers = 1
 	}
+	if opts.NGram <= 0 {
+		opts.NGram = 1
+	}
+	if opts.Logger == nil {
+		opts.Logger = logging.Discard()
+	}
+	if opts.Tokenizer == nil {
+		opts.Tokenizer = DefaultTokenizer{Bank: wordBank}
+	}
+	for _, weight := range []*int{&opts.Weights.Title, &opts.Weights.Subhead, &opts.Weights.Body} {
+		if *weight <= 0 {
+			*weight = 1
+		}
+	}
 
 	bufferSize := numWorkers * 2
-	return &WorkerPool{
-		wordBank:   wordBank,
+	if opts.JobBuffer <= 0 {
+		opts.JobBuffer = bufferSize
+	}
+	if opts.ResultBuffer <= 0 {
+		opts.ResultBuffer = bufferSize
+	}
+
+	wp := &WorkerPool{
 		numWorkers: numWorkers,
-		jobs:       make(chan string, bufferSize),
-		results:    make(chan map[string]int, bufferSize),
+		options:    opts,
+		ctx:        context.Background(),
+		jobs:       make(chan job, opts.JobBuffer),
+		results:    make(chan map[string]int, opts.ResultBuffer),
+		errors:     make(chan error, opts.ResultBuffer),
+		metrics:    &poolMetrics{},
 		wg:         &sync.WaitGroup{},
 	}
+	wp.process = wp.countWords
+	if wp.options.Sink == nil {
+		wp.options.Sink = channelSink{pool: wp}
+	}
+	return wp
 }
 
 func (wp *WorkerPool) Start() {
+	wp.StartWithContext(context.Background())
+}
+
+// StartWithContext starts the workers so that they stop taking new jobs once
+// ctx is cancelled. A job already being processed is finished first.
+func (wp *WorkerPool) StartWithContext(ctx context.Context) {
+	wp.ctx = ctx
+	wp.metrics.started.Store(time.Now().UnixNano())
 	for i := 0; i < wp.numWorkers; i++ {
 		wp.wg.Add(1)
-		go wp.worker()
+		go wp.worker(ctx)
 	}
 }
 
-func (wp *WorkerPool) worker() {
+func (wp *WorkerPool) worker(ctx context.Context) {
 	defer wp.wg.Done()
 
-	for content := range wp.jobs {
-		wordCounts := make(map[string]int)
-		processedWords := ProcessContent(content, wp.wordBank)
+	var aggregate map[string]int
+	if wp.options.AggregatePerWorker {
+		aggregate = make(map[string]int)
+	}
 
-		for _, word := range processedWords {
-			wordCounts[word]++
+	for {
+		select {
+		case <-ctx.Done():
+			// keep what was counted before the cancellation, as per-job mode does
+			if len(aggregate) > 0 {
+				wp.flush(aggregate)
+			}
+			return
+		case j, ok := <-wp.jobs:
+			if !ok {
+				if aggregate != nil {
+					wp.flush(aggregate)
+				}
+				return
+			}
+
+			start := time.Now()
+			wordCounts, err := wp.recoverJob(j)
+			wp.metrics.processingTime.Add(int64(time.Since(start)))
+			wp.metrics.jobsProcessed.Add(1)
+			wp.metrics.lastFinished.Store(time.Now().UnixNano())
+			if err != nil {
+				select {
+				case wp.errors <- &ProcessingError{Input: j.input(), Err: err}:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			if aggregate != nil {
+				for word, count := range wordCounts {
+					aggregate[word] += count
+				}
+				continue
+			}
+
+			if sink, ok := wp.options.Sink.(SourceSink); ok {
+				sink.AcceptFrom(j.source, wordCounts)
+				continue
+			}
+			wp.options.Sink.Accept(wordCounts)
 		}
+	}
+}
 
-		wp.results <- wordCounts
+// flush hands a worker's aggregate to the sink. The default sink sends it
+// without giving up on a cancelled ctx, which is safe since Results must be
+// drained until Close.
+func (wp *WorkerPool) flush(aggregate map[string]int) {
+	if sink, ok := wp.options.Sink.(channelSink); ok {
+		sink.pool.results <- aggregate
+		return
 	}
+	wp.options.Sink.Accept(aggregate)
 }
 
-func (wp *WorkerPool) Submit(content string) {
-	wp.jobs <- content
+// recoverJob runs processJob, turning a panic into an ErrWorkerPanic error
+// so one poison document can't kill the worker and hang Close.
+func (wp *WorkerPool) recoverJob(j job) (wordCounts map[string]int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			wp.options.Logger.Error("Recovered from worker panic", "panic", r)
+			wordCounts, err = nil, fmt.Errorf("%w: %v", ErrWorkerPanic, r)
+		}
+	}()
+	return wp.processJob(j)
+}
+
+func (wp *WorkerPool) processJob(j job) (map[string]int, error) {
+	if j.doc == nil {
+		return wp.process(j.content)
+	}
+
+	wordCounts := make(map[string]int)
+	parts := []struct {
+		text   string
+		weight int
+	}{
+		{j.doc.Title, wp.options.Weights.Title},
+		{j.doc.Subhead, wp.options.Weights.Subhead},
+		{j.doc.Body, wp.options.Weights.Body},
+	}
+	for _, part := range parts {
+		if part.text == "" {
+			continue
+		}
+		counts, err := wp.process(part.text)
+		if err != nil {
+			return nil, err
+		}
+		for word, count := range counts {
+			wordCounts[word] += count * part.weight
+		}
+	}
+	return wordCounts, nil
+}
+
+func (j job) input() string {
+	if j.doc == nil {
+		return j.content
+	}
+	return strings.Join([]string{j.doc.Title, j.doc.Subhead, j.doc.Body}, " ")
+}
+
+func (wp *WorkerPool) countWords(content string) (map[string]int, error) {
+	wordCounts := make(map[string]int)
+	var processedWords []string
+	if tokenizer, ok := wp.options.Tokenizer.(RunTokenizer); ok && wp.options.NGram > 1 {
+		for _, run := range tokenizer.TokenizeRuns(content) {
+			processedWords = append(processedWords, BuildNGrams(run, wp.options.NGram)...)
+		}
+	} else {
+		processedWords = BuildNGrams(wp.options.Tokenizer.Tokenize(content), wp.options.NGram)
+	}
+
+	for _, word := range processedWords {
+		wordCounts[word]++
+	}
+	wp.metrics.wordsProcessed.Add(int64(len(processedWords)))
+	return wordCounts, nil
+}
+
+func (wp *WorkerPool) Submit(content string) error {
+	if wp.ctx.Err() != nil {
+		return ErrPoolShuttingDown
+	}
+
+	return wp.submit(job{content: content})
+}
+
+// SubmitFrom queues content like Submit, recording source for a SourceSink.
+func (wp *WorkerPool) SubmitFrom(source, content string) error {
+	if wp.ctx.Err() != nil {
+		return ErrPoolShuttingDown
+	}
+
+	return wp.submit(job{content: content, source: source})
+}
+
+// SubmitDocument queues doc to be counted with the pool's Weights, so words
+// in its title can count more than words in its body.
+func (wp *WorkerPool) SubmitDocument(doc Document) error {
+	if wp.ctx.Err() != nil {
+		return ErrPoolShuttingDown
+	}
+
+	return wp.submit(job{doc: &doc, source: doc.Source})
+}
+
+func (wp *WorkerPool) submit(j job) error {
+	select {
+	case wp.jobs <- j:
+		return nil
+	case <-wp.ctx.Done():
+		return ErrPoolShuttingDown
+	}
 }
 
 func (wp *WorkerPool) Close() {
+	_ = wp.CloseWithTimeout(0)
+}
+
+// CloseWithTimeout stops accepting jobs and waits up to d for the workers to
+// finish, or indefinitely when d is not positive. On timeout the result and
+// error channels are still closed once the remaining workers exit.
+func (wp *WorkerPool) CloseWithTimeout(d time.Duration) error {
 	close(wp.jobs)
-	wp.wg.Wait()
-	close(wp.results)
+
+	done := make(chan struct{})
+	go func() {
+		wp.wg.Wait()
+		close(wp.results)
+		close(wp.errors)
+		close(done)
+	}()
+
+	if d <= 0 {
+		<-done
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(d):
+		return fmt.Errorf("%w after %v", ErrPoolCloseTimeout, d)
+	}
 }
 
+// Results delivers each job's word counts unless the pool was given a Sink,
+// in which case it is closed without receiving any.
 func (p *WorkerPool) Results() <-chan map[string]int {
 	return p.results
 }
 
+func (p *WorkerPool) PoolMetrics() PoolMetrics {
+	metrics := PoolMetrics{
+		JobsProcessed:  p.metrics.jobsProcessed.Load(),
+		WordsProcessed: p.metrics.wordsProcessed.Load(),
+		ProcessingTime: time.Duration(p.metrics.processingTime.Load()),
+	}
+	if metrics.JobsProcessed > 0 {
+		metrics.AvgProcessingTime = metrics.ProcessingTime / time.Duration(metrics.JobsProcessed)
+	}
+	if started, finished := p.metrics.started.Load(), p.metrics.lastFinished.Load(); started > 0 && finished > started {
+		metrics.ElapsedTime = time.Duration(finished - started)
+	}
+	return metrics
+}
+
+// WordsPerSecond is the pool's counting throughput over wall-clock time,
+// so it grows with the number of workers.
+func (m PoolMetrics) WordsPerSecond() float64 {
+	if m.ElapsedTime <= 0 {
+		return 0
+	}
+	return float64(m.WordsProcessed) / m.ElapsedTime.Seconds()
+}
+
+// Errors reports documents that failed processing. It must be drained
+// alongside Results, and is closed by Close.
+func (p *WorkerPool) Errors() <-chan error {
+	return p.errors
+}
+
+// GetWords returns the bank sorted alphabetically, one word per line, so the
+// saved bank is stable across runs.
 func (p *ValidWordBank) GetWords() string {
+	p.mu.RLock()
 	words := make([]string, 0, len(p.words))
 	for word := range p.words {
 		words = append(words, word)
 	}
+	p.mu.RUnlock()
+
+	sort.Strings(words)
 	return strings.Join(words, "\n")
 }
 
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
 type SafeWordCounter struct {
 	mu     sync.RWMutex
 	counts map[string]int
+	order  map[string]int // position each word was first counted at, for TieBreakInsertion
 }
 
 func NewSafeWordCounter() *SafeWordCounter {
 	return &SafeWordCounter{
 		counts: make(map[string]int),
+		order:  make(map[string]int),
 	}
 }
 
 func (c *SafeWordCounter) Increment(word string, count int) {
 	c.mu.Lock()
+	if _, seen := c.counts[word]; !seen {
+		c.order[word] = len(c.order)
+	}
 	c.counts[word] += count
 	c.mu.Unlock()
 }
 
+// Accept adds wordCounts to c, so a counter can be a worker pool's Sink. New
+// words are ordered alphabetically among themselves for TieBreakInsertion.
+func (c *SafeWordCounter) Accept(wordCounts map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var added []string
+	for word, count := range wordCounts {
+		if _, seen := c.counts[word]; !seen {
+			added = append(added, word)
+		}
+		c.counts[word] += count
+	}
+	sort.Strings(added)
+	for _, word := range added {
+		c.order[word] = len(c.order)
+	}
+}
+
+// Merge adds the counts of other into c.
+func (c *SafeWordCounter) Merge(other *SafeWordCounter) {
+	if other == nil {
+		return
+	}
+
+	if other == c {
+		c.mu.Lock()
+		for word, count := range c.counts {
+			c.counts[word] = count * 2
+		}
+		c.mu.Unlock()
+		return
+	}
+
+	// lock in address order so concurrent a.Merge(b) and b.Merge(a) can't deadlock
+	if uintptr(unsafe.Pointer(c)) < uintptr(unsafe.Pointer(other)) {
+		c.mu.Lock()
+		other.mu.RLock()
+	} else {
+		other.mu.RLock()
+		c.mu.Lock()
+	}
+	defer c.mu.Unlock()
+	defer other.mu.RUnlock()
+
+	var added []string
+	for word, count := range other.counts {
+		if _, seen := c.counts[word]; !seen {
+			added = append(added, word)
+		}
+		c.counts[word] += count
+	}
+
+	// new words keep the order they were first seen in other
+	sort.Slice(added, func(i, j int) bool { return other.order[added[i]] < other.order[added[j]] })
+	for _, word := range added {
+		c.order[word] = len(c.order)
+	}
+}
+
+// SaveCounts writes every word count to path as JSON so a later run can
+// continue from it with LoadCounts.
+func (c *SafeWordCounter) SaveCounts(path string) error {
+	c.mu.RLock()
+	data, err := json.Marshal(c.counts)
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("marshal counts: %w", err)
+	}
+
+	// write then rename, so a crash mid-write leaves the previous counts
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadCounts reads counts written by SaveCounts. A missing file yields an
+// empty counter so the first run starts fresh.
+func LoadCounts(path string) (*SafeWordCounter, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewSafeWordCounter(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read counts: %w", err)
+	}
+
+	counter := NewSafeWordCounter()
+	if err := json.Unmarshal(data, &counter.counts); err != nil {
+		return nil, fmt.Errorf("parse counts %s: %w", path, err)
+	}
+	if counter.counts == nil {
+		counter.counts = make(map[string]int)
+	}
+
+	// the file doesn't record insertion order, so loaded words take theirs
+	// alphabetically
+	words := make([]string, 0, len(counter.counts))
+	for word := range counter.counts {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	for i, word := range words {
+		counter.order[word] = i
+	}
+	return counter, nil
+}
+
+func (c *SafeWordCounter) Reset() {
+	c.mu.Lock()
+	c.counts = make(map[string]int)
+	c.order = make(map[string]int)
+	c.mu.Unlock()
+}
+
+func (c *SafeWordCounter) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.counts)
+}
+
+func (c *SafeWordCounter) GetCount(word string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.counts[word]
+}
+
+func (c *SafeWordCounter) TotalWords() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	total := 0
+	for _, count := range c.counts {
+		total += count
+	}
+	return total
+}
+
+// Snapshot returns a copy of every word and its count.
+func (c *SafeWordCounter) Snapshot() map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return maps.Clone(c.counts)
+}
+
+// LengthHistogram maps each word length, in characters, to the total number
+// of occurrences of words that long.
+func (c *SafeWordCounter) LengthHistogram() map[int]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	histogram := make(map[int]int)
+	for word, count := range c.counts {
+		histogram[utf8.RuneCountInString(word)] += count
+	}
+	return histogram
+}
+
+func (c *SafeWordCounter) GetTopWords(n int) []WordCount {
+	return c.GetTopWordsWithTieBreak(n, TieBreakAlphabetical)
+}
+
+// TieBreak orders words that share a count.
+type TieBreak int
+
+const (
+	TieBreakAlphabetical        TieBreak = iota // "apple" before "banana", the default
+	TieBreakReverseAlphabetical                 // "banana" before "apple"
+	TieBreakInsertion                           // whichever word was counted first
+)
+
+func (c *SafeWordCounter) GetTopWordsWithTieBreak(n int, tieBreak TieBreak) []WordCount {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return rankWordCountsBy(c.counts, n, false, c.tieBreakLess(tieBreak))
+}
+
+// tieBreakLess must be called with c.mu held, as TieBreakInsertion reads c.order.
+func (c *SafeWordCounter) tieBreakLess(tieBreak TieBreak) func(a, b string) bool {
+	switch tieBreak {
+	case TieBreakReverseAlphabetical:
+		return func(a, b string) bool { return a > b }
+	case TieBreakInsertion:
+		return func(a, b string) bool { return c.order[a] < c.order[b] }
+	default:
+		return alphabetical
+	}
+}
+
+func (c *SafeWordCounter) GetTopWordCountsWithTieBreak(topN int, tieBreak TieBreak) []map[string]int {
+	return toWordCountMaps(c.GetTopWordsWithTieBreak(topN, tieBreak))
+}
+
+// SnapshotTop ranks the counts so far. The read lock is held only while the
+// counts are copied, not while they're sorted, so it can be polled during a
+// crawl without stalling the collector.
+func (c *SafeWordCounter) SnapshotTop(n int) []WordCount {
+	if n <= 0 {
+		return nil
+	}
+
+	c.mu.RLock()
+	wcList := make([]WordCount, 0, len(c.counts))
+	for word, count := range c.counts {
+		wcList = append(wcList, WordCount{Word: word, Count: count})
+	}
+	c.mu.RUnlock()
+
+	sortWordCounts(wcList, false, alphabetical)
+	return wcList[:min(n, len(wcList))]
+}
+
 func (c *SafeWordCounter) GetTopWordCounts(topN int) []map[string]int {
+	return toWordCountMaps(c.GetTopWords(topN))
+}
+
+func (c *SafeWordCounter) GetBottomWordCounts(n int) []map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return toWordCountMaps(rankWordCounts(c.counts, n, true))
+}
+
+// DocumentFrequencyCounter counts each word at most once per document, so a
+// word's count is the number of documents it appears in.
+type DocumentFrequencyCounter struct {
+	mu        sync.RWMutex
+	counts    map[string]int
+	terms     map[string]int
+	documents int
+}
+
+type WordScore struct {
+	Word  string  `json:"word"`
+	Score float64 `json:"score"`
+}
+
+func NewDocumentFrequencyCounter() *DocumentFrequencyCounter {
+	return &DocumentFrequencyCounter{
+		counts: make(map[string]int),
+		terms:  make(map[string]int),
+	}
+}
+
+func (c *DocumentFrequencyCounter) AddDocument(wordCounts map[string]int) {
+	c.mu.Lock()
+	for word, count := range wordCounts {
+		c.counts[word]++
+		c.terms[word] += count
+	}
+	c.documents++
+	c.mu.Unlock()
+}
+
+func (c *DocumentFrequencyCounter) Documents() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.documents
+}
+
+func (c *DocumentFrequencyCounter) GetTopWords(n int) []WordCount {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return rankWordCounts(c.counts, n, false)
+}
+
+func (c *DocumentFrequencyCounter) GetTopWordCounts(topN int) []map[string]int {
+	return toWordCountMaps(c.GetTopWords(topN))
+}
+
+// TopTFIDF ranks words by their total occurrences times the log of the inverse
+// fraction of documents they appear in.
+func (c *DocumentFrequencyCounter) TopTFIDF(topN int) []WordScore {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -154,30 +1417,66 @@ func (c *SafeWordCounter) GetTopWordCounts(topN int) []map[string]int {
 		return nil
 	}
 
-	wcList := make([]struct {
-		word  string
-		count int
-	}, 0, len(c.counts))
+	scores := make([]WordScore, 0, len(c.counts))
+	for word, df := range c.counts {
+		idf := math.Log(float64(c.documents) / float64(df))
+		scores = append(scores, WordScore{Word: word, Score: float64(c.terms[word]) * idf})
+	}
 
-	for word, count := range c.counts {
-		wcList = append(wcList, struct {
-			word  string
-			count int
-		}{word, count})
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score == scores[j].Score {
+			return scores[i].Word < scores[j].Word
+		}
+		return scores[i].Score > scores[j].Score
+	})
+
+	return scores[:min(topN, len(scores))]
+}
+
+// rankWordCounts orders words by count, descending unless ascending is set,
+// breaking ties alphabetically.
+func rankWordCounts(counts map[string]int, topN int, ascending bool) []WordCount {
+	return rankWordCountsBy(counts, topN, ascending, alphabetical)
+}
+
+// rankWordCountsBy is rankWordCounts with tiesBefore deciding the order of
+// words with equal counts.
+func rankWordCountsBy(counts map[string]int, topN int, ascending bool, tiesBefore func(a, b string) bool) []WordCount {
+	if topN <= 0 {
+		return nil
+	}
+
+	wcList := make([]WordCount, 0, len(counts))
+	for word, count := range counts {
+		wcList = append(wcList, WordCount{Word: word, Count: count})
 	}
 
+	sortWordCounts(wcList, ascending, tiesBefore)
+	return wcList[:min(topN, len(wcList))]
+}
+
+func sortWordCounts(wcList []WordCount, ascending bool, tiesBefore func(a, b string) bool) {
 	sort.Slice(wcList, func(i, j int) bool {
-		if wcList[i].count == wcList[j].count {
-			return wcList[i].word < wcList[j].word
+		if wcList[i].Count == wcList[j].Count {
+			return tiesBefore(wcList[i].Word, wcList[j].Word)
 		}
-		return wcList[i].count > wcList[j].count
+		if ascending {
+			return wcList[i].Count < wcList[j].Count
+		}
+		return wcList[i].Count > wcList[j].Count
 	})
+}
 
-	resultLen := min(topN, len(wcList))
-	topWords := make([]map[string]int, resultLen)
-	for i := 0; i < resultLen; i++ {
-		topWords[i] = map[string]int{wcList[i].word: wcList[i].count}
+func alphabetical(a, b string) bool { return a < b }
+
+func toWordCountMaps(wordCounts []WordCount) []map[string]int {
+	if wordCounts == nil {
+		return nil
 	}
 
-	return topWords
+	maps := make([]map[string]int, len(wordCounts))
+	for i, wc := range wordCounts {
+		maps[i] = map[string]int{wc.Word: wc.Count}
+	}
+	return maps
 }
@@ -1,11 +1,22 @@
 package processor
 
 import (
-	"sort"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProcessValidWordBank(t *testing.T) {
@@ -17,12 +28,12 @@ func TestProcessValidWordBank(t *testing.T) {
 		{
 			name:     "valid words",
 			rawWords: []string{"hello", "world", "test"},
-			want:     []string{"hello", "world", "test"},
+			want:     []string{"hello", "test", "world"},
 		},
 		{
 			name:     "mixed case words",
 			rawWords: []string{"Hello", "WORLD", "Test"},
-			want:     []string{"hello", "world", "test"},
+			want:     []string{"hello", "test", "world"},
 		},
 		{
 			name:     "invalid words filtered",
@@ -34,14 +45,96 @@ func TestProcessValidWordBank(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			vwb := ProcessValidWordBank(tt.rawWords)
-			got := strings.Split(strings.TrimSpace(vwb.GetWords()), "\n")
-			sort.Strings(got)
-			sort.Strings(tt.want)
+			got := strings.Split(vwb.GetWords(), "\n")
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
+func TestProcessValidWordBankFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	general := filepath.Join(dir, "general.txt")
+	glossary := filepath.Join(dir, "glossary.txt")
+	require.NoError(t, os.WriteFile(general, []byte("hello\nworld\nhi\n"), 0644))
+	require.NoError(t, os.WriteFile(glossary, []byte("Kubernetes\r\nhello\r\n"), 0644))
+
+	vwb, err := ProcessValidWordBankFromFiles(general, glossary)
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello\nkubernetes\nworld", vwb.GetWords())
+
+	_, err = ProcessValidWordBankFromFiles(general, filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestProcessValidWordBankFromFilesDelimited(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		delimiter string
+	}{
+		{"crlf", "hello\r\nworld\r\n\r\n", "\n"},
+		{"byte order mark", "\ufeffhello\nworld\n", "\n"},
+		{"comma", "hello, world,,", ","},
+		{"whitespace", "hello \t world\r\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bank.txt")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			vwb, err := ProcessValidWordBankFromFilesDelimited(tt.delimiter, path)
+			require.NoError(t, err)
+
+			assert.Equal(t, "hello\nworld", vwb.GetWords())
+			assert.Equal(t, []string{"hello", "world"}, ProcessContent("hello world", vwb))
+		})
+	}
+}
+
+func TestValidWordBankAddRemove(t *testing.T) {
+	vwb := ProcessValidWordBank([]string{"hello"})
+
+	assert.True(t, vwb.AddWord("World"))
+	assert.True(t, vwb.IsValid("world"))
+	assert.False(t, vwb.AddWord("world"))
+	assert.False(t, vwb.AddWord("hi"))
+	assert.False(t, vwb.AddWord("test1"))
+
+	assert.True(t, vwb.RemoveWord("HELLO"))
+	assert.False(t, vwb.IsValid("hello"))
+	assert.False(t, vwb.RemoveWord("hello"))
+	assert.False(t, vwb.RemoveWord("hi"))
+}
+
+func TestValidWordBankConcurrentAccess(t *testing.T) {
+	vwb := ProcessValidWordBank([]string{"hello", "world"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			vwb.AddWord("earth")
+			vwb.RemoveWord("earth")
+		}()
+		go func() {
+			defer wg.Done()
+			vwb.IsValid("earth")
+			ProcessContent("hello earth world", vwb)
+		}()
+		go func() {
+			defer wg.Done()
+			vwb.GetWords()
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, vwb.IsValid("hello"))
+	assert.False(t, vwb.IsValid("earth"))
+}
+
 func TestProcessContent(t *testing.T) {
 	wordBank := ProcessValidWordBank([]string{"hello", "world", "test"})
 
@@ -80,13 +173,426 @@ func TestProcessContent(t *testing.T) {
 	}
 }
 
+// processContentFields is the original strings.Fields based tokenizer, kept
+// as a reference for the streaming implementation.
+func processContentFields(content string, wordBank *ValidWordBank) []string {
+	words := strings.Fields(content)
+	validWords := make([]string, 0, len(words))
+	buf := make([]byte, 0, 32)
+
+	for _, word := range words {
+		buf = buf[:0]
+		for i := 0; i < len(word); i++ {
+			c := word[i]
+			if c >= 'A' && c <= 'Z' {
+				buf = append(buf, c+32)
+			} else if c >= 'a' && c <= 'z' {
+				buf = append(buf, c)
+			}
+		}
+
+		if len(buf) >= 3 && wordBank.IsValid(string(buf)) {
+			validWords = append(validWords, string(buf))
+		}
+	}
+	return validWords
+}
+
+func TestProcessContentMatchesFields(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world", "test", "cafe", "state"})
+
+	inputs := []string{
+		"",
+		"   ",
+		"hello",
+		"  hello\tworld\ntest  ",
+		"hello\u00a0world\u2003test",
+		"caf\u00e9 state-of-the-art hello,world",
+		"\xffhello \xfe world",
+		"HELLO\r\nWorld\vtest\fhello",
+	}
+
+	for _, input := range inputs {
+		assert.Equal(t, processContentFields(input, wordBank), ProcessContent(input, wordBank), "input %q", input)
+	}
+}
+
+func benchmarkArticle() string {
+	paragraph := "The quick brown fox jumps over the lazy dog, while engineers at the " +
+		"company announced a new product that would change the industry forever. " +
+		"Analysts said the device's battery life, display and price were impressive! "
+	return strings.Repeat(paragraph, 200)
+}
+
+// largeBenchmarkArticle is a multi-megabyte document, large enough for
+// ProcessContentParallel to split.
+func largeBenchmarkArticle() string {
+	return strings.Repeat(benchmarkArticle(), 100)
+}
+
+func benchmarkWordBank() *ValidWordBank {
+	return ProcessValidWordBank(strings.Fields("the quick brown fox jumps over lazy dog while " +
+		"engineers company announced new product that would change industry forever " +
+		"analysts said device battery life display and price were impressive"))
+}
+
+func BenchmarkProcessContent(b *testing.B) {
+	content, wordBank := benchmarkArticle(), benchmarkWordBank()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProcessContent(content, wordBank)
+	}
+}
+
+func TestProcessValidWordBankCtx(t *testing.T) {
+	rawWords := []string{"hello", "World", "bad1", "ok"}
+
+	wordBank, err := ProcessValidWordBankCtx(context.Background(), rawWords)
+	require.NoError(t, err)
+	assert.Equal(t, ProcessValidWordBank(rawWords).GetWords(), wordBank.GetWords())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	wordBank, err = ProcessValidWordBankCtx(ctx, rawWords)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, wordBank)
+}
+
+// largeRawWordBank is a dictionary big enough to be built in shards, with
+// duplicates and invalid entries spread across it.
+func largeRawWordBank() []string {
+	rawWords := make([]string, 0, 500000)
+	for i := 0; len(rawWords) < cap(rawWords); i++ {
+		word := []byte{'a' + byte(i%26), 'a' + byte(i/26%26), 'a' + byte(i/676%26), 'a' + byte(i/17576%26)}
+		rawWords = append(rawWords, string(word))
+		if i%10 == 0 {
+			rawWords = append(rawWords, strings.ToUpper(string(word)), string(word)+"1")
+		}
+	}
+	return rawWords
+}
+
+func TestBuildWordSetShards(t *testing.T) {
+	rawWords := largeRawWordBank()
+
+	serial, err := buildWordSet(context.Background(), rawWords, WordOptions{}, 1)
+	require.NoError(t, err)
+	for _, shards := range []int{2, 3, 8} {
+		sharded, err := buildWordSet(context.Background(), rawWords, WordOptions{}, shards)
+		require.NoError(t, err)
+		assert.Equal(t, serial, sharded, "shards %d", shards)
+	}
+
+	small, err := buildWordSet(context.Background(), []string{"one", "two"}, WordOptions{}, 8)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"one": "one", "two": "two"}, small)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = buildWordSet(ctx, rawWords, WordOptions{}, 4)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkProcessValidWordBankSerial(b *testing.B) {
+	rawWords := largeRawWordBank()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = buildWordSet(context.Background(), rawWords, WordOptions{}, 1)
+	}
+}
+
+func BenchmarkProcessValidWordBankParallel(b *testing.B) {
+	rawWords := largeRawWordBank()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = buildWordSet(context.Background(), rawWords, WordOptions{}, runtime.GOMAXPROCS(0))
+	}
+}
+
+func TestIsValidBytes(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world"})
+
+	assert.True(t, wordBank.IsValidBytes([]byte("hello")))
+	assert.False(t, wordBank.IsValidBytes([]byte("HELLO")))
+	assert.False(t, wordBank.IsValidBytes(nil))
+
+	word := []byte("world")
+	allocs := testing.AllocsPerRun(10, func() {
+		wordBank.IsValidBytes(word)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestProcessContentAllocations(t *testing.T) {
+	content, wordBank := benchmarkArticle(), benchmarkWordBank()
+
+	// bank words reuse the bank's strings, leaving only the result slice
+	allocs := testing.AllocsPerRun(10, func() {
+		ProcessContent(content, wordBank)
+	})
+	assert.LessOrEqual(t, allocs, 1.0)
+}
+
+func BenchmarkProcessContentFields(b *testing.B) {
+	content, wordBank := benchmarkArticle(), benchmarkWordBank()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		processContentFields(content, wordBank)
+	}
+}
+
+func TestProcessContentParallel(t *testing.T) {
+	wordBank := benchmarkWordBank()
+	small := benchmarkArticle()
+	assert.Equal(t, ProcessContent(small, wordBank), ProcessContentParallel(small, wordBank))
+
+	large := largeBenchmarkArticle()
+	require.GreaterOrEqual(t, len(large), parallelThreshold)
+	assert.Equal(t, ProcessContent(large, wordBank), ProcessContentParallel(large, wordBank))
+}
+
+func TestSplitAtSpace(t *testing.T) {
+	content := "alpha beta\tgamma\ndelta epsilon"
+	chunks := splitAtSpace(content, 3)
+
+	assert.Len(t, chunks, 3)
+	assert.Equal(t, content, strings.Join(chunks, ""))
+	for _, chunk := range chunks[1:] {
+		assert.Contains(t, " \t\n", chunk[:1])
+	}
+
+	assert.Equal(t, []string{"nospaceshere"}, splitAtSpace("nospaceshere", 4))
+}
+
+func BenchmarkProcessContentLarge(b *testing.B) {
+	content, wordBank := largeBenchmarkArticle(), benchmarkWordBank()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProcessContent(content, wordBank)
+	}
+}
+
+func BenchmarkProcessContentParallelLarge(b *testing.B) {
+	content, wordBank := largeBenchmarkArticle(), benchmarkWordBank()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProcessContentParallel(content, wordBank)
+	}
+}
+
+func TestProcessContentKeepApostrophes(t *testing.T) {
+	opts := WordOptions{KeepApostrophes: true}
+	wordBank := ProcessValidWordBankWithOptions([]string{"don't", "can\u2019t", "won't", "its", "it's", "dogs"}, opts)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "contractions",
+			content: "don't can't won't",
+			want:    []string{"don't", "can't", "won't"},
+		},
+		{
+			name:    "typographic apostrophe",
+			content: "Don\u2019t it\u2019s",
+			want:    []string{"don't", "it's"},
+		},
+		{
+			name:    "possessive and quotes",
+			content: "'its' dogs' it's",
+			want:    []string{"its", "dogs", "it's"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProcessContent(tt.content, wordBank)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	defaultBank := ProcessValidWordBank([]string{"don't", "dont"})
+	assert.Equal(t, []string{"dont"}, ProcessContent("don't", defaultBank))
+}
+
+func TestProcessContentHyphens(t *testing.T) {
+	rawWords := []string{"state-of-the-art", "stateoftheart", "state", "the", "art", "well-known"}
+	content := "A state-of-the-art, well--known design-"
+
+	tests := []struct {
+		name string
+		mode HyphenMode
+		want []string
+	}{
+		{
+			name: "drop",
+			mode: HyphenDrop,
+			want: []string{"stateoftheart"},
+		},
+		{
+			name: "split",
+			mode: HyphenSplit,
+			want: []string{"state", "the", "art"},
+		},
+		{
+			name: "keep",
+			mode: HyphenKeep,
+			want: []string{"state-of-the-art", "well-known"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wordBank := ProcessValidWordBankWithOptions(rawWords, WordOptions{Hyphens: tt.mode})
+			assert.Equal(t, tt.want, ProcessContent(content, wordBank))
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	wordBank := ProcessValidWordBankWithOptions([]string{"cat", "the", "elephant", "running", "ox"}, WordOptions{MinLength: 2})
+	content := "The cat, the ox and the elephant were running"
+
+	tests := []struct {
+		name string
+		opts WordOptions
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"the", "cat", "the", "the", "elephant", "running"},
+		},
+		{
+			name: "min length",
+			opts: WordOptions{MinLength: 2},
+			want: []string{"the", "cat", "the", "ox", "the", "elephant", "running"},
+		},
+		{
+			name: "max length",
+			opts: WordOptions{MaxLength: 7},
+			want: []string{"the", "cat", "the", "the", "running"},
+		},
+		{
+			name: "stop words",
+			opts: WordOptions{StopWords: map[string]struct{}{"the": {}}},
+			want: []string{"cat", "elephant", "running"},
+		},
+		{
+			name: "filter",
+			opts: WordOptions{MinLength: 2, Filter: regexp.MustCompile(`ing$|^.{2}$`)},
+			want: []string{"ox", "running"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Tokenize(content, wordBank, tt.opts))
+		})
+	}
+
+	assert.Equal(t, Tokenize(content, wordBank, WordOptions{MinLength: 2}), ProcessContent(content, wordBank))
+
+	filtered := ProcessValidWordBankWithOptions([]string{"cat", "the", "elephant", "running"}, WordOptions{Filter: regexp.MustCompile(`^[ce]`)})
+	assert.Equal(t, []string{"cat", "elephant"}, ProcessContent(content, filtered))
+}
+
+func TestProcessContentNilBank(t *testing.T) {
+	assert.Equal(t, []string{"every", "token", "counts", "here"}, ProcessContent("Every token counts, go here!", nil))
+	assert.Equal(t, []string{"every", "token", "counts"}, Tokenize("Every token counts, go here!", nil, WordOptions{MinLength: 5, MaxLength: 6}))
+}
+
+func TestProcessContentCaseSensitive(t *testing.T) {
+	rawWords := []string{"Apple", "apple", "iPhone", "Bad1"}
+	content := "Apple sells the iPhone, not an apple or an APPLE"
+
+	insensitive := ProcessValidWordBank(rawWords)
+	assert.Equal(t, []string{"apple", "iphone", "apple", "apple"}, ProcessContent(content, insensitive))
+
+	sensitive := ProcessValidWordBankWithOptions(rawWords, WordOptions{CaseSensitive: true})
+	assert.Equal(t, "Apple\napple\niPhone", sensitive.GetWords())
+	assert.Equal(t, []string{"Apple", "iPhone", "apple"}, ProcessContent(content, sensitive))
+}
+
+func TestProcessContentAllowDigits(t *testing.T) {
+	rawWords := []string{"covid19", "MP3", "4k", "2024", "usb", "g5's"}
+	content := "Covid19 cases, MP3 players, 4K screens and 2024 USB drives"
+
+	digits := ProcessValidWordBankWithOptions(rawWords, WordOptions{AllowDigits: true, MinLength: 2})
+	assert.Equal(t, "4k\ncovid19\nmp3\nusb", digits.GetWords())
+	assert.Equal(t, []string{"covid19", "mp3", "4k", "usb"}, ProcessContent(content, digits))
+
+	assert.Equal(t, []string{"covid", "cases", "players", "screens", "and", "usb", "drives"}, ProcessContent(content, nil))
+	assert.Equal(t, []string{"covid19", "cases", "mp3", "players", "screens", "and", "usb", "drives"}, Tokenize(content, nil, WordOptions{AllowDigits: true}))
+
+	contractions := ProcessValidWordBankWithOptions(rawWords, WordOptions{AllowDigits: true, KeepApostrophes: true})
+	assert.True(t, contractions.IsValid("g5's"))
+}
+
+func TestProcessContentUnicodeLetters(t *testing.T) {
+	rawWords := []string{"Café", "ÉCOLE", "naïve", "plain"}
+	content := "CAFÉ and école, a naïve plain text"
+
+	ascii := ProcessValidWordBank(rawWords)
+	assert.Equal(t, "plain", ascii.GetWords())
+	assert.Equal(t, []string{"plain"}, ProcessContent(content, ascii))
+
+	unicodeBank := ProcessValidWordBankWithOptions(rawWords, WordOptions{UnicodeLetters: true})
+	assert.Equal(t, "café\nnaïve\nplain\nécole", unicodeBank.GetWords())
+	assert.Equal(t, []string{"café", "école", "naïve", "plain"}, ProcessContent(content, unicodeBank))
+
+	assert.Equal(t, []string{"café", "and", "école", "naïve", "plain", "text"},
+		Tokenize(content, nil, WordOptions{UnicodeLetters: true}))
+}
+
+func TestNormalizeBankWordHyphens(t *testing.T) {
+	keep := WordOptions{Hyphens: HyphenKeep}
+
+	word, ok := normalizeBankWord("State-Of-The-Art", keep)
+	assert.True(t, ok)
+	assert.Equal(t, "state-of-the-art", word)
+
+	for _, invalid := range []string{"-art", "art-", "state--art", "a-1"} {
+		_, ok := normalizeBankWord(invalid, keep)
+		assert.False(t, ok, invalid)
+	}
+
+	_, ok = normalizeBankWord("state-of-the-art", WordOptions{})
+	assert.False(t, ok)
+}
+
+func TestIsContraction(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"won't", true},
+		{"hello", true},
+		{"'tis", false},
+		{"dogs'", false},
+		{"rock'n'roll", false},
+		{"won't!", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, isContraction(tt.input))
+		})
+	}
+}
+
 func TestWorkerPool(t *testing.T) {
 	wordBank := ProcessValidWordBank([]string{"hello", "world", "test", "earth"})
 	wp := NewWorkerPool(wordBank, -2)
 	wp.Start()
 
-	wp.Submit("hello world test")
-	wp.Submit("hello test")
+	assert.NoError(t, wp.Submit("hello world test"))
+	assert.NoError(t, wp.Submit("hello test"))
 	wp.Close()
 
 	totalCounts := make(map[string]int)
@@ -101,6 +607,465 @@ func TestWorkerPool(t *testing.T) {
 	assert.Equal(t, 2, totalCounts["test"])
 }
 
+func TestWorkerPoolNilBank(t *testing.T) {
+	wp := NewWorkerPool(nil, 2)
+	wp.Start()
+
+	assert.NoError(t, wp.Submit("Zyzzyva and the qwerty zyzzyva"))
+	wp.Close()
+
+	totalCounts := make(map[string]int)
+	for result := range wp.Results() {
+		for word, count := range result {
+			totalCounts[word] += count
+		}
+	}
+
+	assert.Equal(t, map[string]int{"zyzzyva": 2, "and": 1, "the": 1, "qwerty": 1}, totalCounts)
+}
+
+func TestWorkerPoolSubmitDocumentWeights(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"market", "rally", "stocks", "today"})
+	doc := Document{Title: "Stocks rally", Subhead: "Market today", Body: "Stocks rose today as the market cheered"}
+
+	tests := []struct {
+		name    string
+		weights Weights
+		want    map[string]int
+	}{
+		{
+			name: "default weights",
+			want: map[string]int{"stocks": 2, "rally": 1, "market": 2, "today": 2},
+		},
+		{
+			name:    "title counts three times",
+			weights: Weights{Title: 3, Subhead: 2},
+			want:    map[string]int{"stocks": 4, "rally": 3, "market": 3, "today": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wp := NewWorkerPoolWithOptions(wordBank, 1, WorkerPoolOptions{Weights: tt.weights})
+			wp.Start()
+			assert.NoError(t, wp.SubmitDocument(doc))
+			wp.Close()
+
+			assert.Equal(t, tt.want, <-wp.Results())
+		})
+	}
+}
+
+func TestWorkerPoolTokenizer(t *testing.T) {
+	numbers := TokenizerFunc(func(content string) []string {
+		var tokens []string
+		for _, field := range strings.Fields(content) {
+			if strings.Trim(field, "0123456789") == "" {
+				tokens = append(tokens, field)
+			}
+		}
+		return tokens
+	})
+
+	wp := NewWorkerPoolWithOptions(nil, 1, WorkerPoolOptions{Tokenizer: numbers})
+	wp.Start()
+	assert.NoError(t, wp.Submit("In 2024 sales rose 15 percent, up from 15 in 2023"))
+	wp.Close()
+
+	assert.Equal(t, map[string]int{"2024": 1, "15": 2, "2023": 1}, <-wp.Results())
+
+	wordBank := ProcessValidWordBank([]string{"sales", "rose"})
+	assert.Equal(t, []string{"sales", "rose"}, DefaultTokenizer{Bank: wordBank}.Tokenize("In 2024 sales rose"))
+}
+
+func TestWorkerPoolSink(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world", "test"})
+
+	for _, aggregate := range []bool{false, true} {
+		counter := NewSafeWordCounter()
+		wp := NewWorkerPoolWithOptions(wordBank, 3, WorkerPoolOptions{Sink: counter, AggregatePerWorker: aggregate})
+		wp.Start()
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, wp.Submit("hello world hello test"))
+		}
+		wp.Close()
+
+		_, ok := <-wp.Results()
+		assert.False(t, ok, "results channel should be closed and empty")
+		assert.Equal(t, []WordCount{{Word: "hello", Count: 20}, {Word: "test", Count: 10}, {Word: "world", Count: 10}}, counter.GetTopWords(3))
+	}
+}
+
+func TestWorkerPoolSourceSink(t *testing.T) {
+	var mu sync.Mutex
+	bySource := make(map[string]map[string]int)
+	sink := SourceSinkFunc(func(source string, wordCounts map[string]int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if bySource[source] == nil {
+			bySource[source] = make(map[string]int)
+		}
+		for word, count := range wordCounts {
+			bySource[source][word] += count
+		}
+	})
+
+	wp := NewWorkerPoolWithOptions(nil, 2, WorkerPoolOptions{Sink: sink})
+	wp.Start()
+	assert.NoError(t, wp.SubmitFrom("a.com", "hello world"))
+	assert.NoError(t, wp.SubmitDocument(Document{Body: "hello there", Source: "b.com"}))
+	assert.NoError(t, wp.Submit("anonymous"))
+	wp.Close()
+
+	assert.Equal(t, map[string]map[string]int{
+		"a.com": {"hello": 1, "world": 1},
+		"b.com": {"hello": 1, "there": 1},
+		"":      {"anonymous": 1},
+	}, bySource)
+}
+
+func TestSafeWordCounterAccept(t *testing.T) {
+	counter := NewSafeWordCounter()
+	counter.Increment("zebra", 1)
+	counter.Accept(map[string]int{"zebra": 1, "beta": 2, "alpha": 2})
+
+	assert.Equal(t, []WordCount{{Word: "alpha", Count: 2}, {Word: "beta", Count: 2}, {Word: "zebra", Count: 2}}, counter.GetTopWords(3))
+	assert.Equal(t, []WordCount{{Word: "zebra", Count: 2}, {Word: "alpha", Count: 2}, {Word: "beta", Count: 2}}, counter.GetTopWordsWithTieBreak(3, TieBreakInsertion))
+}
+
+func TestWorkerPoolAggregatePerWorker(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world", "test"})
+	wp := NewWorkerPoolWithOptions(wordBank, 2, WorkerPoolOptions{AggregatePerWorker: true})
+	wp.Start()
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, wp.Submit("hello world test"))
+	}
+	wp.Close()
+
+	emitted := 0
+	totalCounts := make(map[string]int)
+	for result := range wp.Results() {
+		emitted++
+		for word, count := range result {
+			totalCounts[word] += count
+		}
+	}
+
+	assert.Equal(t, 2, emitted)
+	assert.Equal(t, map[string]int{"hello": 10, "world": 10, "test": 10}, totalCounts)
+}
+
+func TestWorkerPoolMetrics(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world", "test"})
+	wp := NewWorkerPool(wordBank, 2)
+	assert.Zero(t, wp.PoolMetrics().AvgProcessingTime)
+	assert.Zero(t, wp.PoolMetrics().WordsPerSecond())
+	wp.Start()
+
+	assert.NoError(t, wp.Submit("hello world test"))
+	assert.NoError(t, wp.Submit("hello unknown"))
+	wp.Close()
+	for range wp.Results() {
+	}
+
+	metrics := wp.PoolMetrics()
+	assert.Equal(t, int64(2), metrics.JobsProcessed)
+	assert.Equal(t, int64(4), metrics.WordsProcessed)
+	assert.Equal(t, metrics.ProcessingTime/2, metrics.AvgProcessingTime)
+	assert.Positive(t, metrics.ElapsedTime)
+}
+
+func TestWorkerPoolMetricsElapsedIsWallClock(t *testing.T) {
+	wp := NewWorkerPool(ProcessValidWordBank([]string{"hello"}), 4)
+	process := wp.process
+	wp.process = func(content string) (map[string]int, error) {
+		time.Sleep(50 * time.Millisecond)
+		return process(content)
+	}
+	wp.Start()
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, wp.Submit("hello"))
+	}
+	wp.Close()
+	for range wp.Results() {
+	}
+
+	metrics := wp.PoolMetrics()
+	assert.Less(t, metrics.ElapsedTime, metrics.ProcessingTime)
+	assert.Equal(t, float64(metrics.WordsProcessed)/metrics.ElapsedTime.Seconds(), metrics.WordsPerSecond())
+}
+
+func TestWorkerPoolCloseWithTimeout(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello"})
+
+	wp := NewWorkerPool(wordBank, 1)
+	wp.Start()
+	assert.NoError(t, wp.Submit("hello"))
+	assert.NoError(t, wp.CloseWithTimeout(time.Second))
+
+	release := make(chan struct{})
+	wp = NewWorkerPool(wordBank, 1)
+	wp.process = func(content string) (map[string]int, error) {
+		<-release
+		return wp.countWords(content)
+	}
+	wp.Start()
+	assert.NoError(t, wp.Submit("hello"))
+
+	err := wp.CloseWithTimeout(10 * time.Millisecond)
+	assert.ErrorIs(t, err, ErrPoolCloseTimeout)
+
+	close(release)
+	for range wp.Results() {
+	}
+}
+
+func TestWorkerPoolErrors(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello"})
+	wp := NewWorkerPool(wordBank, 1)
+	errMalformed := errors.New("malformed document")
+	wp.process = func(content string) (map[string]int, error) {
+		if content == "bad" {
+			return nil, errMalformed
+		}
+		return wp.countWords(content)
+	}
+	wp.Start()
+
+	assert.NoError(t, wp.Submit("bad"))
+	assert.NoError(t, wp.Submit("hello"))
+	wp.Close()
+
+	var results []map[string]int
+	for result := range wp.Results() {
+		results = append(results, result)
+	}
+	assert.Equal(t, []map[string]int{{"hello": 1}}, results)
+
+	var errs []error
+	for err := range wp.Errors() {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], errMalformed)
+
+	var procErr *ProcessingError
+	require.ErrorAs(t, errs[0], &procErr)
+	assert.Equal(t, "bad", procErr.Input)
+}
+
+func TestWorkerPoolRecoversPanic(t *testing.T) {
+	var logs bytes.Buffer
+	wp := NewWorkerPoolWithOptions(nil, 1, WorkerPoolOptions{Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+	wp.process = func(content string) (map[string]int, error) {
+		if content == "poison" {
+			panic("malformed input")
+		}
+		return wp.countWords(content)
+	}
+	wp.Start()
+
+	assert.NoError(t, wp.Submit("poison"))
+	assert.NoError(t, wp.Submit("hello"))
+	wp.Close()
+
+	var results []map[string]int
+	for result := range wp.Results() {
+		results = append(results, result)
+	}
+	assert.Equal(t, []map[string]int{{"hello": 1}}, results)
+
+	var errs []error
+	for err := range wp.Errors() {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrWorkerPanic)
+	assert.ErrorContains(t, errs[0], "malformed input")
+
+	var procErr *ProcessingError
+	require.ErrorAs(t, errs[0], &procErr)
+	assert.Equal(t, "poison", procErr.Input)
+	assert.Contains(t, logs.String(), `level=ERROR msg="Recovered from worker panic" panic="malformed input"`)
+}
+
+func TestNewWorkerPoolWithOptionsBuffers(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello"})
+
+	wp := NewWorkerPool(wordBank, 3)
+	assert.Equal(t, 6, cap(wp.jobs))
+	assert.Equal(t, 6, cap(wp.results))
+
+	wp = NewWorkerPoolWithOptions(wordBank, 3, WorkerPoolOptions{JobBuffer: 4, ResultBuffer: 100})
+	assert.Equal(t, 4, cap(wp.jobs))
+	assert.Equal(t, 100, cap(wp.results))
+}
+
+func TestWorkerPoolStartWithContext(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world"})
+	wp := NewWorkerPool(wordBank, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	wp.StartWithContext(ctx)
+
+	assert.NoError(t, wp.Submit("hello world"))
+	result := <-wp.Results()
+	assert.Equal(t, map[string]int{"hello": 1, "world": 1}, result)
+
+	cancel()
+	assert.ErrorIs(t, wp.Submit("hello"), ErrPoolShuttingDown)
+
+	done := make(chan struct{})
+	go func() {
+		wp.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("workers did not stop after context cancellation")
+	}
+}
+
+func TestWorkerPoolAggregateFlushedOnCancel(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world"})
+	counter := NewSafeWordCounter()
+	wp := NewWorkerPoolWithOptions(wordBank, 2, WorkerPoolOptions{Sink: counter, AggregatePerWorker: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	wp.StartWithContext(ctx)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, wp.Submit("hello world"))
+	}
+	assert.Eventually(t, func() bool { return wp.PoolMetrics().JobsProcessed == 3 }, time.Second, time.Millisecond)
+
+	cancel()
+	wp.Close()
+
+	assert.Equal(t, 3, counter.GetCount("hello"))
+	assert.Equal(t, 3, counter.GetCount("world"))
+}
+
+func TestWorkerPoolAggregateFlushedOnCancelToResults(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world"})
+	for run := 0; run < 50; run++ {
+		wp := NewWorkerPoolWithOptions(wordBank, 2, WorkerPoolOptions{AggregatePerWorker: true})
+		ctx, cancel := context.WithCancel(context.Background())
+		wp.StartWithContext(ctx)
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, wp.Submit("hello world"))
+		}
+		assert.Eventually(t, func() bool { return wp.PoolMetrics().JobsProcessed == 3 }, time.Second, time.Millisecond)
+
+		cancel()
+		totals := make(map[string]int)
+		done := make(chan struct{})
+		go func() {
+			for result := range wp.Results() {
+				for word, count := range result {
+					totals[word] += count
+				}
+			}
+			close(done)
+		}()
+		wp.Close()
+		<-done
+
+		require.Equal(t, map[string]int{"hello": 3, "world": 3}, totals, "run %d", run)
+	}
+}
+
+func TestBuildNGrams(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		n     int
+		want  []string
+	}{
+		{
+			name:  "unigrams unchanged",
+			words: []string{"artificial", "intelligence", "rocks"},
+			n:     1,
+			want:  []string{"artificial", "intelligence", "rocks"},
+		},
+		{
+			name:  "bigrams",
+			words: []string{"artificial", "intelligence", "rocks"},
+			n:     2,
+			want:  []string{"artificial intelligence", "intelligence rocks"},
+		},
+		{
+			name:  "fewer words than n",
+			words: []string{"artificial"},
+			n:     2,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildNGrams(tt.words, tt.n)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTokenizeRuns(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"machine", "learning", "deep"})
+	tests := []struct {
+		name    string
+		content string
+		want    [][]string
+	}{
+		{"adjacent", "Machine learning", [][]string{{"machine", "learning"}}},
+		{"dropped word between", "machine is not learning", [][]string{{"machine"}, {"learning"}}},
+		{"dropped at the edges", "the deep machine learning club", [][]string{{"deep", "machine", "learning"}}},
+		{"extra spaces", "deep   learning", [][]string{{"deep", "learning"}}},
+		{"empty", "", [][]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TokenizeRuns(tt.content, wordBank, WordOptions{}))
+		})
+	}
+}
+
+func TestWorkerPoolNGramSkipsDroppedWords(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"machine", "learning"})
+	wp := NewWorkerPoolWithOptions(wordBank, 1, WorkerPoolOptions{NGram: 2})
+	wp.Start()
+
+	assert.NoError(t, wp.Submit("machine is not learning, machine learning"))
+	wp.Close()
+
+	assert.Equal(t, map[string]int{"learning machine": 1, "machine learning": 1}, <-wp.Results())
+}
+
+func TestWorkerPoolNGram(t *testing.T) {
+	wordBank := ProcessValidWordBank([]string{"hello", "world", "test"})
+	wp := NewWorkerPoolWithOptions(wordBank, 2, WorkerPoolOptions{NGram: 2})
+	wp.Start()
+
+	assert.NoError(t, wp.Submit("hello world test"))
+	assert.NoError(t, wp.Submit("hello world"))
+	wp.Close()
+
+	totalCounts := make(map[string]int)
+	for result := range wp.Results() {
+		for phrase, count := range result {
+			totalCounts[phrase] += count
+		}
+	}
+
+	assert.Equal(t, 2, totalCounts["hello world"])
+	assert.Equal(t, 1, totalCounts["world test"])
+	assert.Equal(t, 0, totalCounts["hello"])
+}
+
 func TestSafeWordCounter(t *testing.T) {
 	counter := NewSafeWordCounter()
 
@@ -146,6 +1111,255 @@ func TestSafeWordCounter(t *testing.T) {
 	}
 }
 
+func TestGetTopWords(t *testing.T) {
+	counter := NewSafeWordCounter()
+
+	counter.Increment("hello", 2)
+	counter.Increment("world", 1)
+	counter.Increment("earth", 1)
+	counter.Increment("test", 3)
+
+	assert.Equal(t, []WordCount{
+		{Word: "test", Count: 3},
+		{Word: "hello", Count: 2},
+		{Word: "earth", Count: 1},
+	}, counter.GetTopWords(3))
+	assert.Nil(t, counter.GetTopWords(0))
+}
+
+func TestSafeWordCounterAccessors(t *testing.T) {
+	counter := NewSafeWordCounter()
+	assert.Equal(t, 0, counter.TotalWords())
+
+	counter.Increment("hello", 2)
+	counter.Increment("world", 1)
+	counter.Increment("hello", 3)
+
+	assert.Equal(t, 5, counter.GetCount("hello"))
+	assert.Equal(t, 1, counter.GetCount("world"))
+	assert.Equal(t, 0, counter.GetCount("missing"))
+	assert.Equal(t, 6, counter.TotalWords())
+}
+
+func TestSafeWordCounterSnapshot(t *testing.T) {
+	counter := NewSafeWordCounter()
+	counter.Increment("hello", 2)
+	counter.Increment("world", 1)
+
+	snapshot := counter.Snapshot()
+	assert.Equal(t, map[string]int{"hello": 2, "world": 1}, snapshot)
+
+	snapshot["hello"] = 100
+	counter.Increment("new", 1)
+	assert.Equal(t, 2, counter.GetCount("hello"))
+	assert.NotContains(t, snapshot, "new")
+}
+
+func TestSafeWordCounterLengthHistogram(t *testing.T) {
+	counter := NewSafeWordCounter()
+	assert.Empty(t, counter.LengthHistogram())
+
+	counter.Increment("cat", 2)
+	counter.Increment("dog", 3)
+	counter.Increment("house", 1)
+	counter.Increment("café", 4)
+
+	assert.Equal(t, map[int]int{3: 5, 4: 4, 5: 1}, counter.LengthHistogram())
+}
+
+func TestSafeWordCounterSnapshotTop(t *testing.T) {
+	counter := NewSafeWordCounter()
+	assert.Empty(t, counter.SnapshotTop(3))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			counter.Increment("busy", 1)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		counter.SnapshotTop(3)
+	}
+	wg.Wait()
+
+	counter.Increment("beta", 5)
+	counter.Increment("alpha", 5)
+	assert.Equal(t, []WordCount{{Word: "busy", Count: 1000}, {Word: "alpha", Count: 5}}, counter.SnapshotTop(2))
+	assert.Equal(t, counter.GetTopWords(3), counter.SnapshotTop(3))
+	assert.Nil(t, counter.SnapshotTop(0))
+}
+
+func TestSafeWordCounterTieBreak(t *testing.T) {
+	counter := NewSafeWordCounter()
+	counter.Increment("mango", 2)
+	counter.Increment("apple", 2)
+	counter.Increment("zebra", 5)
+	counter.Increment("kiwi", 2)
+	counter.Increment("mango", 0)
+
+	tests := []struct {
+		name     string
+		tieBreak TieBreak
+		want     []string
+	}{
+		{name: "alphabetical", tieBreak: TieBreakAlphabetical, want: []string{"zebra", "apple", "kiwi", "mango"}},
+		{name: "reverse alphabetical", tieBreak: TieBreakReverseAlphabetical, want: []string{"zebra", "mango", "kiwi", "apple"}},
+		{name: "insertion", tieBreak: TieBreakInsertion, want: []string{"zebra", "mango", "apple", "kiwi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var words []string
+			for _, wc := range counter.GetTopWordsWithTieBreak(4, tt.tieBreak) {
+				words = append(words, wc.Word)
+			}
+			assert.Equal(t, tt.want, words)
+		})
+	}
+
+	assert.Equal(t, counter.GetTopWords(4), counter.GetTopWordsWithTieBreak(4, TieBreakAlphabetical))
+	assert.Equal(t, []map[string]int{{"zebra": 5}, {"mango": 2}}, counter.GetTopWordCountsWithTieBreak(2, TieBreakInsertion))
+
+	merged := NewSafeWordCounter()
+	merged.Increment("kiwi", 2)
+	merged.Merge(counter)
+	top := merged.GetTopWordsWithTieBreak(4, TieBreakInsertion)
+	assert.Equal(t, []WordCount{{"zebra", 5}, {"kiwi", 4}, {"mango", 2}, {"apple", 2}}, top)
+}
+
+func TestSafeWordCounterReset(t *testing.T) {
+	counter := NewSafeWordCounter()
+	counter.Increment("hello", 2)
+	counter.Increment("world", 1)
+	assert.Equal(t, 2, counter.Len())
+
+	counter.Reset()
+	assert.Equal(t, 0, counter.Len())
+	assert.Equal(t, 0, counter.TotalWords())
+
+	counter.Increment("test", 1)
+	assert.Equal(t, 1, counter.Len())
+	assert.Equal(t, 1, counter.GetCount("test"))
+}
+
+func TestSafeWordCounterMerge(t *testing.T) {
+	a := NewSafeWordCounter()
+	a.Increment("hello", 2)
+	a.Increment("world", 1)
+
+	b := NewSafeWordCounter()
+	b.Increment("hello", 3)
+	b.Increment("test", 4)
+
+	a.Merge(b)
+	assert.Equal(t, 5, a.GetCount("hello"))
+	assert.Equal(t, 1, a.GetCount("world"))
+	assert.Equal(t, 4, a.GetCount("test"))
+	assert.Equal(t, 3, b.GetCount("hello"))
+
+	a.Merge(nil)
+	assert.Equal(t, 10, a.TotalWords())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.Merge(b)
+		}()
+		go func() {
+			defer wg.Done()
+			b.Merge(a)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSaveAndLoadCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counts.json")
+
+	fresh, err := LoadCounts(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, fresh.Len())
+
+	counter := NewSafeWordCounter()
+	counter.Increment("hello", 2)
+	counter.Increment("world", 5)
+	require.NoError(t, counter.SaveCounts(path))
+
+	loaded, err := LoadCounts(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.GetCount("hello"))
+	assert.Equal(t, 5, loaded.GetCount("world"))
+
+	loaded.Increment("hello", 1)
+	assert.Equal(t, 3, loaded.GetCount("hello"))
+
+	require.NoError(t, os.WriteFile(path, []byte("null"), 0644))
+	loaded, err = LoadCounts(path)
+	require.NoError(t, err)
+	loaded.Increment("hello", 1)
+	assert.Equal(t, 1, loaded.GetCount("hello"))
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0644))
+	_, err = LoadCounts(path)
+	assert.Error(t, err)
+}
+
+func TestGetBottomWordCounts(t *testing.T) {
+	counter := NewSafeWordCounter()
+
+	counter.Increment("hello", 2)
+	counter.Increment("world", 1)
+	counter.Increment("earth", 1)
+	counter.Increment("test", 3)
+
+	assert.Equal(t, []map[string]int{
+		{"earth": 1},
+		{"world": 1},
+		{"hello": 2},
+	}, counter.GetBottomWordCounts(3))
+	assert.Len(t, counter.GetBottomWordCounts(10), 4)
+	assert.Nil(t, counter.GetBottomWordCounts(0))
+	assert.Nil(t, counter.GetBottomWordCounts(-1))
+}
+
+func TestDocumentFrequencyCounter(t *testing.T) {
+	counter := NewDocumentFrequencyCounter()
+
+	counter.AddDocument(map[string]int{"hello": 50})
+	counter.AddDocument(map[string]int{"world": 1, "test": 2})
+	counter.AddDocument(map[string]int{"world": 3})
+
+	assert.Equal(t, 3, counter.Documents())
+	assert.Equal(t, []map[string]int{
+		{"world": 2},
+		{"hello": 1},
+		{"test": 1},
+	}, counter.GetTopWordCounts(3))
+	assert.Nil(t, counter.GetTopWordCounts(0))
+}
+
+func TestTopTFIDF(t *testing.T) {
+	counter := NewDocumentFrequencyCounter()
+
+	counter.AddDocument(map[string]int{"the": 5, "rocket": 4})
+	counter.AddDocument(map[string]int{"the": 6, "garden": 1})
+	counter.AddDocument(map[string]int{"the": 4, "rocket": 1})
+
+	got := counter.TopTFIDF(3)
+	assert.Len(t, got, 3)
+	assert.Equal(t, "rocket", got[0].Word)
+	assert.InDelta(t, 5*math.Log(3.0/2.0), got[0].Score, 1e-9)
+	assert.Equal(t, "garden", got[1].Word)
+	assert.InDelta(t, math.Log(3.0), got[1].Score, 1e-9)
+	assert.Equal(t, "the", got[2].Word)
+	assert.Zero(t, got[2].Score)
+	assert.Nil(t, counter.TopTFIDF(0))
+}
+
 func TestIsAlpha(t *testing.T) {
 	tests := []struct {
 		input string
@@ -0,0 +1,52 @@
+package processor
+
+import "unicode"
+
+// Stats are document-level counts for readability metrics.
+type Stats struct {
+	Characters int `json:"characters"` // runes other than whitespace
+	Words      int `json:"words"`      // whitespace-separated fields, as strings.Fields
+	Sentences  int `json:"sentences"`  // runs of text ending in '.', '!' or '?', or at the end
+}
+
+// Add returns the sum of s and other, to total stats across documents.
+func (s Stats) Add(other Stats) Stats {
+	return Stats{
+		Characters: s.Characters + other.Characters,
+		Words:      s.Words + other.Words,
+		Sentences:  s.Sentences + other.Sentences,
+	}
+}
+
+// TextStats counts the characters, words and sentences of content. Runs of
+// terminators such as "?!" or "..." end one sentence, and trailing text
+// without one still counts as a sentence. It doesn't use a word bank.
+func TextStats(content string) Stats {
+	var stats Stats
+	inWord, inSentence := false, false
+	for _, r := range content {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+
+		stats.Characters++
+		if !inWord {
+			stats.Words++
+			inWord = true
+		}
+		switch r {
+		case '.', '!', '?':
+			if inSentence {
+				stats.Sentences++
+				inSentence = false
+			}
+		default:
+			inSentence = true
+		}
+	}
+	if inSentence {
+		stats.Sentences++
+	}
+	return stats
+}
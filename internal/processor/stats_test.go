@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Stats
+	}{
+		{"empty", "", Stats{}},
+		{"whitespace", " \n\t", Stats{}},
+		{"one sentence", "Hello world.", Stats{Characters: 11, Words: 2, Sentences: 1}},
+		{"several", "Is it? Yes! It is.", Stats{Characters: 14, Words: 5, Sentences: 3}},
+		{"runs of terminators", "Wait... what?! Really", Stats{Characters: 19, Words: 3, Sentences: 3}},
+		{"no terminator", "just words", Stats{Characters: 9, Words: 2, Sentences: 1}},
+		{"stray terminator", ". !", Stats{Characters: 2, Words: 2}},
+		{"non-ASCII", "Café naïve.", Stats{Characters: 10, Words: 2, Sentences: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TextStats(tt.content))
+		})
+	}
+}
+
+func TestStatsAdd(t *testing.T) {
+	total := TextStats("One. Two.").Add(TextStats("Three words here!"))
+
+	assert.Equal(t, Stats{Characters: 23, Words: 5, Sentences: 3}, total)
+}